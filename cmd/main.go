@@ -1,26 +1,293 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/config"
 	"github.com/aziz-shoko/goblog/internal/handler"
+	"github.com/aziz-shoko/goblog/internal/logging"
 	"github.com/aziz-shoko/goblog/internal/service"
 	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/internal/web"
 )
 
 func main() {
-	postStore := store.NewInMemoryStore()
-	postService := service.NewPostService(postStore)
+	cfg, err := config.Load(os.Args[0], os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	// everything below logs through slog, the log package included
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.Level())
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger)
+
+	authn, err := buildAuthenticator(cfg.APIKeys, cfg.JWTSecret, cfg.JWTPublicKey)
+	if err != nil {
+		fatal("auth setup failed", err)
+	}
+	requireAuth := handler.AuthMiddleware(authn)
+	optionalAuth := handler.OptionalAuthMiddleware(authn)
+
+	stores, err := openStores(cfg.Store, cfg.SQLitePath, cfg.PostgresDSN)
+	if err != nil {
+		fatal("opening the store failed", err)
+	}
+
+	// every store call is counted, the gauges and counters are served on GET /metrics
+	registry := prometheus.NewRegistry()
+	posts := service.NewInstrumentedPostStore(stores.posts, registry)
+
+	postService := service.NewPostService(posts,
+		service.WithCommentStore(stores.comments),
+		service.WithValidationPolicy(validationPolicy(cfg)),
+	)
+	postService.RegisterMetrics(registry)
+
+	// publishes scheduled posts and empties the trash in the background for as long as the server runs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	jobs.Add(2)
+	go func() {
+		defer jobs.Done()
+		postService.RunScheduler(jobsCtx, cfg.PublishInterval)
+	}()
+	go func() {
+		defer jobs.Done()
+		postService.RunPurger(jobsCtx, cfg.PurgeInterval, cfg.TrashRetention)
+	}()
+
 	postHandler := handler.NewPostHandler(postService)
+	authorService := service.NewAuthorService(stores.authors)
+	authorHandler := handler.NewAuthorHandler(authorService, postService)
+	commentService := service.NewCommentService(stores.comments, posts)
+	commentHandler := handler.NewCommentHandler(commentService)
+	feedHandler := handler.NewFeedHandler(postService, authorService, cfg.SiteURL())
+	webHandler, err := web.NewHandler(postService)
+	if err != nil {
+		fatal("loading the templates failed", err)
+	}
 
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("POST /posts", handler.LoggingMiddleware(postHandler.CreatePost))
-	mux.HandleFunc("GET /post/{id}", handler.LoggingMiddleware(postHandler.GetPostByID))
-	mux.HandleFunc("GET /posts", handler.LoggingMiddleware(postHandler.GetPostsAll))
-	mux.HandleFunc("DELETE /posts", handler.LoggingMiddleware(postHandler.DeleteAllPosts))
+	// reads are public, anything that changes data needs credentials
+	mux.HandleFunc("POST /posts", handler.LoggingMiddleware(requireAuth(postHandler.CreatePost)))
+	mux.HandleFunc("GET /post/{id}", handler.LoggingMiddleware(optionalAuth(postHandler.GetPostByID)))
+	mux.HandleFunc("GET /posts", handler.LoggingMiddleware(optionalAuth(postHandler.GetPostsAll)))
+	mux.HandleFunc("GET /posts/search", handler.LoggingMiddleware(optionalAuth(postHandler.SearchPosts)))
+	// singular like GET /post/{id}: /posts/by-slug/{slug} would conflict with GET /posts/{id}/revisions
+	// and /comments, ServeMux can't tell which of the two is more specific and panics at startup
+	mux.HandleFunc("GET /post/by-slug/{slug}", handler.LoggingMiddleware(optionalAuth(postHandler.GetPostBySlug)))
+	mux.HandleFunc("PUT /posts/{id}", handler.LoggingMiddleware(requireAuth(postHandler.UpdatePost)))
+	mux.HandleFunc("PATCH /posts/{id}", handler.LoggingMiddleware(requireAuth(postHandler.UpdatePost)))
+	mux.HandleFunc("POST /posts/{id}/publish", handler.LoggingMiddleware(requireAuth(postHandler.PublishPost)))
+	mux.HandleFunc("POST /posts/{id}/unpublish", handler.LoggingMiddleware(requireAuth(postHandler.UnpublishPost)))
+	mux.HandleFunc("POST /posts/{id}/archive", handler.LoggingMiddleware(requireAuth(postHandler.ArchivePost)))
+	mux.HandleFunc("GET /posts/{id}/revisions", handler.LoggingMiddleware(optionalAuth(postHandler.ListRevisions)))
+	mux.HandleFunc("GET /posts/{id}/revisions/diff", handler.LoggingMiddleware(optionalAuth(postHandler.DiffRevisions)))
+	mux.HandleFunc("POST /posts/{id}/revisions/{rev}/restore", handler.LoggingMiddleware(requireAuth(postHandler.RestoreRevision)))
+	mux.HandleFunc("DELETE /posts/{id}", handler.LoggingMiddleware(requireAuth(postHandler.DeletePost)))
+	mux.HandleFunc("DELETE /posts", handler.LoggingMiddleware(requireAuth(handler.RequireRole(auth.RoleAdmin, postHandler.DeleteAllPosts))))
+
+	mux.HandleFunc("GET /trash", handler.LoggingMiddleware(requireAuth(postHandler.ListTrash)))
+	mux.HandleFunc("POST /trash/{id}/restore", handler.LoggingMiddleware(requireAuth(postHandler.RestorePost)))
+
+	mux.HandleFunc("POST /posts/{id}/comments", handler.LoggingMiddleware(requireAuth(commentHandler.CreateComment)))
+	mux.HandleFunc("GET /posts/{id}/comments", handler.LoggingMiddleware(optionalAuth(commentHandler.GetComments)))
+
+	mux.HandleFunc("GET /tags", handler.LoggingMiddleware(postHandler.ListTags))
+	mux.HandleFunc("GET /tags/{tag}/posts", handler.LoggingMiddleware(optionalAuth(postHandler.GetTagPosts)))
+
+	mux.HandleFunc("POST /authors", handler.LoggingMiddleware(requireAuth(authorHandler.CreateAuthor)))
+	mux.HandleFunc("GET /authors", handler.LoggingMiddleware(authorHandler.ListAuthors))
+	mux.HandleFunc("GET /authors/{id}", handler.LoggingMiddleware(authorHandler.GetAuthorByID))
+	mux.HandleFunc("GET /authors/{id}/posts", handler.LoggingMiddleware(optionalAuth(authorHandler.GetAuthorPosts)))
+
+	mux.HandleFunc("GET /feed.rss", handler.LoggingMiddleware(feedHandler.Site(handler.RSS)))
+	mux.HandleFunc("GET /feed.atom", handler.LoggingMiddleware(feedHandler.Site(handler.Atom)))
+	mux.HandleFunc("GET /authors/{id}/feed.rss", handler.LoggingMiddleware(feedHandler.Author(handler.RSS)))
+	mux.HandleFunc("GET /authors/{id}/feed.atom", handler.LoggingMiddleware(feedHandler.Author(handler.Atom)))
+	mux.HandleFunc("GET /tags/{tag}/feed.rss", handler.LoggingMiddleware(feedHandler.Tag(handler.RSS)))
+	mux.HandleFunc("GET /tags/{tag}/feed.atom", handler.LoggingMiddleware(feedHandler.Tag(handler.Atom)))
+
+	// the HTML frontend, everything else on the mux is the JSON API
+	mux.HandleFunc("GET /{$}", handler.LoggingMiddleware(webHandler.Index))
+	mux.HandleFunc("GET /p/{key}", handler.LoggingMiddleware(webHandler.Post))
+	mux.Handle("GET /static/", web.Static())
+
+	// scraped every few seconds, so it stays out of the request log
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	withTimeout := handler.TimeoutMiddleware(cfg.RequestTimeout)
+	withMetrics := handler.MetricsMiddleware(handler.NewHTTPMetrics(registry))
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           withTimeout(withMetrics(mux.ServeHTTP)),
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	slog.Info("starting server", "addr", cfg.Addr, "store", cfg.Store)
+	serveErr := serve(sigCtx, srv, cfg.ShutdownTimeout)
+
+	// the server is drained, nothing can reach the stores any more once the jobs are done
+	stopJobs()
+	jobs.Wait()
+	if err := stores.close(); err != nil {
+		slog.Error("closing the store failed", "error", err)
+	}
+
+	if serveErr != nil {
+		fatal("server failed", serveErr)
+	}
+	slog.Info("server stopped")
+}
+
+// serve runs srv until it fails or ctx is canceled. On cancel it stops accepting connections
+// and gives in-flight requests up to shutdownTimeout to finish before the rest are cut off.
+func serve(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("graceful shutdown: %w", err)
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// fatal logs err and exits, log.Fatal without leaving slog
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// validationPolicy turns the configured content rules into the service's policy
+func validationPolicy(cfg *config.Config) service.ValidationPolicy {
+	return service.ValidationPolicy{
+		MinTitleLength:   cfg.MinTitleLength,
+		MaxTitleLength:   cfg.MaxTitleLength,
+		MinContentLength: cfg.MinContentLength,
+		MaxContentLength: cfg.MaxContentLength,
+		BannedWords:      cfg.BannedWordList(),
+		TitleChars:       cfg.TitleCharsRegexp(),
+		DuplicateTitles:  service.DuplicateScope(cfg.DuplicateTitles),
+	}
+}
+
+// stores bundles every store the server needs, they all live in the same backend.
+// Revisions come with the post store, the post service takes them from there.
+type stores struct {
+	posts    service.PostStore
+	authors  service.AuthorStore
+	comments service.CommentStore
+	close    func() error
+}
+
+// openStores picks the backend, close releases it
+func openStores(storeType, sqlitePath, postgresDSN string) (*stores, error) {
+	switch storeType {
+	case "memory":
+		slog.Info("using memory store")
+		return &stores{
+			posts:    store.NewInMemoryStore(),
+			authors:  store.NewInMemoryAuthorStore(),
+			comments: store.NewInMemoryCommentStore(),
+			close:    func() error { return nil },
+		}, nil
+	case "sqlite":
+		slog.Info("using sqlite store", "path", sqlitePath)
+		s, err := store.NewSQLiteStore(sqlitePath)
+		if err != nil {
+			return nil, err
+		}
+		return &stores{posts: s, authors: s.Authors(), comments: s.Comments(), close: s.Close}, nil
+	case "postgres":
+		// the DSN carries the password, so it stays out of the log
+		slog.Info("using postgres store")
+		s, err := store.NewPostgresStore(postgresDSN)
+		if err != nil {
+			return nil, err
+		}
+		return &stores{posts: s, authors: s.Authors(), comments: s.Comments(), close: s.Close}, nil
+	default:
+		return nil, fmt.Errorf("unknown store type %q", storeType)
+	}
+}
+
+// buildAuthenticator combines every configured credential type, bearer tokens are tried first
+func buildAuthenticator(apiKeys, jwtSecret, jwtPublicKeyPath string) (auth.Authenticator, error) {
+	var chain auth.Chain
+
+	if jwtSecret != "" || jwtPublicKeyPath != "" {
+		config := auth.JWTConfig{Secret: []byte(jwtSecret)}
+		if jwtPublicKeyPath != "" {
+			pemBytes, err := os.ReadFile(jwtPublicKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("read jwt public key: %w", err)
+			}
+			if config.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pemBytes); err != nil {
+				return nil, fmt.Errorf("parse jwt public key: %w", err)
+			}
+		}
+
+		jwtAuthn, err := auth.NewJWTAuthenticator(config)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwtAuthn)
+	}
+
+	if apiKeys != "" {
+		keys, err := auth.ParseAPIKeys(apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, auth.NewAPIKeyAuthenticator(keys))
+	}
 
-	log.Println("Starting server on port 8080...")
-	log.Fatal(http.ListenAndServe(":8080", mux))
+	if len(chain) == 0 {
+		slog.Warn("no api keys or jwt keys configured, every write request will be rejected")
+	}
+	return chain, nil
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// routes reads the patterns main registers on its mux straight from main.go, so the test
+// checks the real route table instead of a copy of it
+func routes(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var patterns []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "HandleFunc" && sel.Sel.Name != "Handle") {
+			return true
+		}
+		if recv, ok := sel.X.(*ast.Ident); !ok || recv.Name != "mux" {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			t.Fatalf("mux route at %v is not a string literal", call.Pos())
+		}
+		pattern, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		patterns = append(patterns, pattern)
+		return true
+	})
+	if len(patterns) == 0 {
+		t.Fatal("Found no routes in main.go")
+	}
+	return patterns
+}
+
+func TestRoutes(t *testing.T) {
+	// ServeMux panics on patterns that conflict, exactly as main would on start
+	mux := http.NewServeMux()
+	for _, pattern := range routes(t) {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("Registering %q: %v", pattern, r)
+				}
+			}()
+			mux.HandleFunc(pattern, func(http.ResponseWriter, *http.Request) {})
+		}()
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/post/1234", "GET /post/{id}"},
+		{http.MethodGet, "/post/by-slug/hello-world", "GET /post/by-slug/{slug}"},
+		{http.MethodGet, "/posts/search", "GET /posts/search"},
+		{http.MethodGet, "/posts/1234/comments", "GET /posts/{id}/comments"},
+		{http.MethodPatch, "/posts/1234", "PATCH /posts/{id}"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			_, pattern := mux.Handler(httptest.NewRequest(tc.method, tc.path, nil))
+			if pattern != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, pattern)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator accepts static keys sent in the X-API-Key header.
+// Keys are kept as SHA-256 digests so the lookup doesn't compare secrets byte by byte.
+type APIKeyAuthenticator struct {
+	keys map[[sha256.Size]byte]Principal
+}
+
+func NewAPIKeyAuthenticator(keys map[string]Principal) *APIKeyAuthenticator {
+	a := &APIKeyAuthenticator{keys: make(map[[sha256.Size]byte]Principal, len(keys))}
+	for key, principal := range keys {
+		a.keys[sha256.Sum256([]byte(key))] = principal
+	}
+	return a
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	principal, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return &principal, nil
+}
+
+// ParseAPIKeys reads keys in the form "key=id[:role|role],key=id",
+// e.g. "s3cret=alice:admin,other=bob" gives alice the admin role and bob none
+func ParseAPIKeys(spec string) (map[string]Principal, error) {
+	keys := map[string]Principal{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, who, ok := strings.Cut(entry, "=")
+		if !ok || key == "" || who == "" {
+			return nil, fmt.Errorf("api key entry %q must look like key=id[:role|role]", entry)
+		}
+
+		id, roles, _ := strings.Cut(who, ":")
+		principal := Principal{ID: id, Name: id}
+		if roles != "" {
+			principal.Roles = strings.Split(roles, "|")
+		}
+		keys[key] = principal
+	}
+	return keys, nil
+}
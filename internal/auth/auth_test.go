@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	keys, err := ParseAPIKeys("s3cret=alice:admin|editor, other=bob")
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	authn := NewAPIKeyAuthenticator(keys)
+
+	tests := []struct {
+		name      string
+		key       string
+		wantID    string
+		wantAdmin bool
+		wantErr   error
+	}{
+		{name: "admin key", key: "s3cret", wantID: "alice", wantAdmin: true},
+		{name: "plain key", key: "other", wantID: "bob"},
+		{name: "unknown key", key: "guess", wantErr: ErrInvalidCredentials},
+		{name: "no key", key: "", wantErr: ErrNoCredentials},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			if tc.key != "" {
+				req.Header.Set(APIKeyHeader, tc.key)
+			}
+
+			principal, err := authn.Authenticate(req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Got error %v wanted %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if principal.ID != tc.wantID || principal.HasRole(RoleAdmin) != tc.wantAdmin {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeys_Invalid(t *testing.T) {
+	for _, spec := range []string{"nokey", "=alice", "key="} {
+		if _, err := ParseAPIKeys(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	secret := []byte("test-secret-that-is-long-enough")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	otherRSAKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	authn, err := NewJWTAuthenticator(JWTConfig{Secret: secret, PublicKey: &rsaKey.PublicKey, Issuer: "goblog"})
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+
+	claims := func(sub string, expiresIn time.Duration) Claims {
+		return Claims{
+			Roles: []string{RoleAdmin},
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   sub,
+				Issuer:    "goblog",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			},
+		}
+	}
+	sign := func(method jwt.SigningMethod, key any, c Claims) string {
+		token, err := jwt.NewWithClaims(method, c).SignedString(key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+
+	wrongIssuer := claims("alice", time.Hour)
+	wrongIssuer.Issuer = "someone-else"
+
+	tests := []struct {
+		name    string
+		header  string
+		wantID  string
+		wantErr error
+	}{
+		{name: "HS256", header: "Bearer " + sign(jwt.SigningMethodHS256, secret, claims("alice", time.Hour)), wantID: "alice"},
+		{name: "RS256", header: "Bearer " + sign(jwt.SigningMethodRS256, rsaKey, claims("bob", time.Hour)), wantID: "bob"},
+		{name: "scheme is case insensitive", header: "bearer " + sign(jwt.SigningMethodHS256, secret, claims("alice", time.Hour)), wantID: "alice"},
+		{name: "expired", header: "Bearer " + sign(jwt.SigningMethodHS256, secret, claims("alice", -time.Minute)), wantErr: ErrInvalidCredentials},
+		{name: "wrong secret", header: "Bearer " + sign(jwt.SigningMethodHS256, []byte("nope"), claims("alice", time.Hour)), wantErr: ErrInvalidCredentials},
+		{name: "wrong rsa key", header: "Bearer " + sign(jwt.SigningMethodRS256, otherRSAKey, claims("bob", time.Hour)), wantErr: ErrInvalidCredentials},
+		{name: "algorithm not allowed", header: "Bearer " + sign(jwt.SigningMethodHS512, secret, claims("alice", time.Hour)), wantErr: ErrInvalidCredentials},
+		{name: "wrong issuer", header: "Bearer " + sign(jwt.SigningMethodHS256, secret, wrongIssuer), wantErr: ErrInvalidCredentials},
+		{name: "no subject", header: "Bearer " + sign(jwt.SigningMethodHS256, secret, claims("", time.Hour)), wantErr: ErrInvalidCredentials},
+		{name: "garbage", header: "Bearer not.a.jwt", wantErr: ErrInvalidCredentials},
+		{name: "other scheme", header: "Basic YWxpY2U6cGFzcw==", wantErr: ErrNoCredentials},
+		{name: "no header", header: "", wantErr: ErrNoCredentials},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			principal, err := authn.Authenticate(req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Got error %v wanted %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && (principal.ID != tc.wantID || !principal.HasRole(RoleAdmin)) {
+				t.Errorf("unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestNewJWTAuthenticator_NeedsAKey(t *testing.T) {
+	if _, err := NewJWTAuthenticator(JWTConfig{}); err == nil {
+		t.Error("expected error without any key")
+	}
+}
+
+func TestChain(t *testing.T) {
+	keys := NewAPIKeyAuthenticator(map[string]Principal{"k": {ID: "alice"}})
+	jwtAuthn, _ := NewJWTAuthenticator(JWTConfig{Secret: []byte("secret")})
+	chain := Chain{jwtAuthn, keys}
+
+	req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+	req.Header.Set(APIKeyHeader, "k")
+	if principal, err := chain.Authenticate(req); err != nil || principal.ID != "alice" {
+		t.Errorf("expected chain to fall through to the api key, got %v %v", principal, err)
+	}
+
+	// a bad bearer token must not be rescued by a valid api key
+	req.Header.Set("Authorization", "Bearer broken")
+	if _, err := chain.Authenticate(req); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Got error %v wanted %v", err, ErrInvalidCredentials)
+	}
+
+	empty := httptest.NewRequest(http.MethodPost, "/posts", nil)
+	if _, err := chain.Authenticate(empty); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Got error %v wanted %v", err, ErrNoCredentials)
+	}
+}
+
+func TestPrincipalContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("empty context should have no principal")
+	}
+
+	ctx := WithPrincipal(context.Background(), &Principal{ID: "alice"})
+	principal, ok := FromContext(ctx)
+	if !ok || principal.ID != "alice" {
+		t.Errorf("expected alice, got %v", principal)
+	}
+}
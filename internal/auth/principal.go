@@ -0,0 +1,63 @@
+// Package auth authenticates API callers and carries who they are through the request context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+var (
+	// ErrNoCredentials means the request didn't try to authenticate at all
+	ErrNoCredentials = errors.New("no credentials provided")
+	// ErrInvalidCredentials means it tried and failed: unknown key, bad signature, expired token...
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller
+type Principal struct {
+	ID    string
+	Name  string
+	Roles []string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Authenticator extracts and verifies credentials from a request.
+// It returns ErrNoCredentials when the request carries none of the kind it understands.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain tries each authenticator in order and uses the first one that finds credentials.
+// Invalid credentials stop the chain, we don't fall through to a weaker scheme.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, authn := range c {
+		principal, err := authn.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal stored by WithPrincipal, if any
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env turns a map into a lookupEnv func
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "goblog.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load("goblog", nil, env(nil))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if *cfg != Default() {
+		t.Errorf("Expected the defaults, got %+v", cfg)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, `{
+		"addr": ":9000",
+		"store": "sqlite",
+		"sqlite_path": "from-file.db",
+		"publish_interval": "30s",
+		"request_timeout": "3s",
+		"purge_interval": "2h"
+	}`)
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(*Config)
+	}{
+		{
+			name: "the file overrides the defaults",
+			args: []string{"-config", file},
+			want: func(c *Config) {
+				c.Addr, c.Store, c.SQLitePath = ":9000", "sqlite", "from-file.db"
+				c.PublishInterval, c.RequestTimeout, c.PurgeInterval = 30*time.Second, 3*time.Second, 2*time.Hour
+			},
+		},
+		{
+			name: "env vars override the file",
+			env:  map[string]string{"GOBLOG_CONFIG": file, "GOBLOG_ADDR": ":7000", "GOBLOG_PUBLISH_INTERVAL": "1s"},
+			want: func(c *Config) {
+				c.Addr, c.Store, c.SQLitePath = ":7000", "sqlite", "from-file.db"
+				c.PublishInterval, c.RequestTimeout, c.PurgeInterval = time.Second, 3*time.Second, 2*time.Hour
+			},
+		},
+		{
+			name: "flags override env vars and the file",
+			args: []string{"-config", file, "-addr", ":6000", "-store", "memory"},
+			env:  map[string]string{"GOBLOG_ADDR": ":7000", "GOBLOG_PURGE_INTERVAL": "5m"},
+			want: func(c *Config) {
+				c.Addr, c.Store, c.SQLitePath = ":6000", "memory", "from-file.db"
+				c.PublishInterval, c.RequestTimeout, c.PurgeInterval = 30*time.Second, 3*time.Second, 5*time.Minute
+			},
+		},
+		{
+			name: "empty env vars are ignored",
+			env:  map[string]string{"GOBLOG_ADDR": ""},
+			want: func(c *Config) {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load("goblog", tc.args, env(tc.env))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			want := Default()
+			tc.want(&want)
+			want.ConfigFile = cfg.ConfigFile
+			if *cfg != want {
+				t.Errorf("Got %+v\nwant %+v", *cfg, want)
+			}
+			if cfg.ConfigFile != file && (tc.args != nil || tc.env["GOBLOG_CONFIG"] != "") {
+				t.Errorf("Expected config file %s, got %q", file, cfg.ConfigFile)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "unknown key in the file",
+			file: `{"adress": ":80"}`,
+			want: []string{`unknown setting "adress"`},
+		},
+		{
+			name: "the file can't include another file",
+			file: `{"config": "other.json"}`,
+			want: []string{`unknown setting "config"`},
+		},
+		{
+			name: "bad duration in the file",
+			file: `{"read_timeout": "soon"}`,
+			want: []string{"read_timeout"},
+		},
+		{
+			name: "malformed file",
+			file: `{"addr": `,
+			want: []string{"goblog.json"},
+		},
+		{
+			name: "bad env var",
+			env:  map[string]string{"GOBLOG_PUBLISH_INTERVAL": "lots"},
+			want: []string{"GOBLOG_PUBLISH_INTERVAL"},
+		},
+		{
+			name: "every validation failure is reported",
+			args: []string{"-store", "postgres", "-log-level", "loud", "-idle-timeout", "0s", "-trash-retention", "-1h", "-min-content-length", "-1", "-log-format", "xml"},
+			want: []string{
+				"postgres store needs postgres-dsn",
+				`unknown log-level "loud"`,
+				`unknown log-format "xml"`,
+				"idle-timeout must be positive",
+				"trash-retention must be positive",
+				"min-content-length and max-content-length must not be negative",
+			},
+		},
+		{
+			name: "validation rules that contradict each other",
+			args: []string{"-min-title-length", "10", "-max-title-length", "5", "-title-chars", `\p{Nope}`, "-duplicate-titles", "sometimes"},
+			want: []string{
+				"max-title-length (5) must not be below min-title-length (10)",
+				"title-chars is not a valid character class",
+				`unknown duplicate-titles "sometimes"`,
+			},
+		},
+		{
+			name: "title chars and banned words that could never work",
+			args: []string{"-title-chars", `a-z]|.*[`, "-banned-words", "spam,buy now,s.p.a.m"},
+			want: []string{
+				"title-chars is not a valid character class: unescaped ] at offset 3",
+				`banned-words entry "buy now" must be a single word`,
+				`banned-words entry "s.p.a.m" must be a single word`,
+			},
+		},
+		{
+			name: "base URL has to be absolute",
+			args: []string{"-base-url", "blog.example.com"},
+			want: []string{`base-url must be an absolute http or https URL without query, got "blog.example.com"`},
+		},
+		{
+			name: "unknown store",
+			env:  map[string]string{"GOBLOG_STORE": "redis"},
+			want: []string{`unknown store "redis"`},
+		},
+		{
+			name: "requests can't outlive the write timeout",
+			args: []string{"-request-timeout", "1m", "-write-timeout", "30s"},
+			want: []string{"request-timeout (1m0s) must not be longer than write-timeout (30s)"},
+		},
+		{
+			name: "stray arguments",
+			args: []string{"serve"},
+			want: []string{`unexpected argument "serve"`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := tc.args
+			if tc.file != "" {
+				args = append([]string{"-config", writeFile(t, tc.file)}, args...)
+			}
+
+			_, err := Load("goblog", args, env(tc.env))
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected %q in %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_Help(t *testing.T) {
+	_, err := Load("goblog", []string{"-h"}, env(nil))
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Expected flag.ErrHelp, got %v", err)
+	}
+}
+
+func TestConfig_ValidationRules(t *testing.T) {
+	cfg, err := Load("goblog", []string{"-banned-words", " spam, ,Scam ", "-title-chars", `\p{L} [:punct:]\]`}, env(nil))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if got := cfg.BannedWordList(); !slices.Equal(got, []string{"spam", "Scam"}) {
+		t.Errorf("Got banned words %q", got)
+	}
+
+	re := cfg.TitleCharsRegexp()
+	for r, want := range map[string]bool{"a": true, "é": true, " ": true, "!": true, "]": true, "7": false, "ab": false} {
+		if got := re.MatchString(r); got != want {
+			t.Errorf("%q: got %v want %v", r, got, want)
+		}
+	}
+
+	defaults := Default()
+	if re := defaults.TitleCharsRegexp(); re != nil {
+		t.Errorf("Expected no title restriction by default, got %s", re)
+	}
+}
+
+func TestConfig_SiteURL(t *testing.T) {
+	tests := []struct {
+		addr    string
+		baseURL string
+		want    string
+	}{
+		{addr: ":8080", want: "http://localhost:8080"},
+		{addr: "0.0.0.0:8080", want: "http://localhost:8080"},
+		{addr: "127.0.0.1:3000", want: "http://127.0.0.1:3000"},
+		{addr: "[::1]:3000", want: "http://[::1]:3000"},
+		{addr: ":8080", baseURL: "https://blog.example.com/", want: "https://blog.example.com"},
+		{addr: ":8080", baseURL: "https://example.com/blog", want: "https://example.com/blog"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.addr+" "+tc.baseURL, func(t *testing.T) {
+			cfg := Default()
+			cfg.Addr, cfg.BaseURL = tc.addr, tc.baseURL
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if got := cfg.SiteURL(); got != tc.want {
+				t.Errorf("Got %s want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("sqlite-path"); got != "GOBLOG_SQLITE_PATH" {
+		t.Errorf("Got %s", got)
+	}
+}
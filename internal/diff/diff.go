@@ -0,0 +1,115 @@
+// Package diff compares two texts line by line
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Op says what happened to a line going from the old text to the new one
+type Op string
+
+const (
+	Equal  Op = " "
+	Insert Op = "+"
+	Delete Op = "-"
+)
+
+// Line is one line of a diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// MaxCells caps the lines of a times the lines of b left after the common start and end are cut,
+// the table comparing them takes four bytes per cell
+const MaxCells = 1 << 22
+
+// ErrTooLarge is returned instead of a diff that would need more than MaxCells
+var ErrTooLarge = errors.New("the texts differ in too many lines to compare")
+
+// Lines returns the shortest edit turning a into b, one entry per line of either text.
+// Lines both texts start or end with are equal lines as they are, the rest goes through
+// the classic longest common subsequence table, which is O(n*m) and so capped by MaxCells.
+// Deletions come before insertions wherever a line was replaced.
+func Lines(a, b string) ([]Line, error) {
+	x, y := split(a), split(b)
+
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, max(len(x), len(y)))
+	for _, text := range x[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	middle, err := lcsLines(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])
+	if err != nil {
+		return nil, err
+	}
+	lines = append(lines, middle...)
+	for _, text := range x[len(x)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	return lines, nil
+}
+
+// lcsLines diffs x and y through the longest common subsequence table
+func lcsLines(x, y []string) ([]Line, error) {
+	if (len(x)+1)*(len(y)+1) > MaxCells {
+		return nil, ErrTooLarge
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int32, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, max(len(x), len(y)))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Op: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Op: Delete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Op: Insert, Text: y[j]})
+	}
+	return lines, nil
+}
+
+// split cuts text into lines, a trailing newline does not start another, empty line
+func split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{name: "both empty", want: []Line{}},
+		{name: "identical", a: "one\ntwo\n", b: "one\ntwo", want: []Line{{Equal, "one"}, {Equal, "two"}}},
+		{name: "from nothing", b: "one\ntwo", want: []Line{{Insert, "one"}, {Insert, "two"}}},
+		{name: "to nothing", a: "one", want: []Line{{Delete, "one"}}},
+		{
+			name: "changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Line{{Equal, "one"}, {Delete, "two"}, {Insert, "2"}, {Equal, "three"}},
+		},
+		{
+			name: "insert and delete",
+			a:    "a\nb\nc\nd",
+			b:    "a\nc\nd\ne",
+			want: []Line{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}, {Equal, "d"}, {Insert, "e"}},
+		},
+		{
+			name: "only the middle changed",
+			a:    "a\nb\nx\ny\nc",
+			b:    "a\nb\nz\nc",
+			want: []Line{{Equal, "a"}, {Equal, "b"}, {Delete, "x"}, {Delete, "y"}, {Insert, "z"}, {Equal, "c"}},
+		},
+		{name: "windows line endings", a: "one\r\ntwo", b: "one\ntwo", want: []Line{{Equal, "one"}, {Equal, "two"}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Lines(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("Lines: %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Lines(%q, %q)\n got %v\nwant %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLines_TooLarge(t *testing.T) {
+	// every line differs, so nothing is cut before the table
+	lines := func(prefix string, n int) string {
+		var b strings.Builder
+		for i := range n {
+			fmt.Fprintf(&b, "%s%d\n", prefix, i)
+		}
+		return b.String()
+	}
+	if _, err := Lines(lines("a", 4000), lines("b", 4000)); err != ErrTooLarge {
+		t.Errorf("got error %v wanted error %v", err, ErrTooLarge)
+	}
+
+	// a small edit to a long text only compares the lines that changed
+	long := lines("line", 10000)
+	got, err := Lines(long, strings.Replace(long, "line5000\n", "changed\n", 1))
+	if err != nil {
+		t.Fatalf("Lines: %v", err)
+	}
+	if len(got) != 10001 || got[5000] != (Line{Delete, "line5000"}) || got[5001] != (Line{Insert, "changed"}) {
+		t.Errorf("unexpected diff around the change: %v", got[4999:5003])
+	}
+}
@@ -0,0 +1,118 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &Feed{
+		Title:   "goblog & friends",
+		Link:    "http://example.com/",
+		Self:    "http://example.com/feed",
+		Updated: published.Add(time.Hour),
+		Items: []Item{
+			{
+				ID:          "http://example.com/p/1",
+				Title:       "Tom & <Jerry>",
+				Link:        "http://example.com/p/1",
+				Author:      "alice",
+				Categories:  []string{"go", "xml"},
+				ContentHTML: `<p>a <strong>bold</strong> &amp; "quoted" claim</p>`,
+				Published:   published,
+				Updated:     published.Add(time.Hour),
+			},
+			{
+				ID:          "http://example.com/p/2",
+				Title:       "Anonymous",
+				Link:        "http://example.com/p/2",
+				ContentHTML: "<p>no author</p>",
+				Published:   published,
+				Updated:     published,
+			},
+		},
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	out, err := testFeed().RSS()
+	if err != nil {
+		t.Fatalf("RSS: %v", err)
+	}
+	doc := string(out)
+
+	// HTML content is escaped text, never markup that would break the document
+	for _, want := range []string{
+		`<rss version="2.0"`,
+		`<title>Tom &amp; &lt;Jerry&gt;</title>`,
+		`<description>&lt;p&gt;a &lt;strong&gt;bold&lt;/strong&gt; &amp;amp; &#34;quoted&#34; claim&lt;/p&gt;</description>`,
+		`<guid isPermaLink="true">http://example.com/p/1</guid>`,
+		`<pubDate>Thu, 02 Jan 2025 03:04:05 +0000</pubDate>`,
+		`<dc:creator>alice</dc:creator>`,
+		`<category>go</category>`,
+		`<atom:link href="http://example.com/feed" rel="self" type="application/rss+xml">`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %s in\n%s", want, doc)
+		}
+	}
+
+	var parsed struct {
+		Items []struct {
+			Description string `xml:"description"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("not well-formed: %v", err)
+	}
+	if got := parsed.Items[0].Description; got != testFeed().Items[0].ContentHTML {
+		t.Errorf("content did not round trip, got %q", got)
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	out, err := testFeed().Atom()
+	if err != nil {
+		t.Fatalf("Atom: %v", err)
+	}
+
+	var parsed struct {
+		ID      string `xml:"id"`
+		Updated string `xml:"updated"`
+		Author  string `xml:"author>name"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Author  string `xml:"author>name"`
+			Content struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("not well-formed: %v", err)
+	}
+
+	if parsed.ID != "http://example.com/feed" || parsed.Updated != "2025-01-02T04:04:05Z" {
+		t.Errorf("unexpected feed header %+v", parsed)
+	}
+	// one entry has no author, so the feed has to name one
+	if parsed.Author != "goblog & friends" {
+		t.Errorf("expected a feed level author, got %q", parsed.Author)
+	}
+
+	entry := parsed.Entries[0]
+	if entry.Title != "Tom & <Jerry>" || entry.Author != "alice" || len(entry.Categories) != 2 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Content.Type != "html" || entry.Content.Value != testFeed().Items[0].ContentHTML {
+		t.Errorf("content did not round trip, got %+v", entry.Content)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// AuthMiddleware only lets requests with valid credentials through and puts the
+// authenticated principal into the request context for the handlers below it.
+// Same decorator shape as LoggingMiddleware, the authenticator is bound up front:
+//
+//	requireAuth := handler.AuthMiddleware(authn)
+//	mux.HandleFunc("POST /posts", handler.LoggingMiddleware(requireAuth(postHandler.CreatePost)))
+func AuthMiddleware(authn auth.Authenticator) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authn.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="goblog"`)
+				writeError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		}
+	}
+}
+
+// OptionalAuthMiddleware is AuthMiddleware for public routes: anonymous requests go through
+// without a principal, but credentials that are sent still have to be valid. Reads use it so
+// authors can see their own drafts.
+func OptionalAuthMiddleware(authn auth.Authenticator) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authn.Authenticate(r)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="goblog"`)
+				writeError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		}
+	}
+}
+
+// RequireRole rejects authenticated callers that lack role, it has to sit inside AuthMiddleware
+func RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok {
+			writeError(w, r, auth.ErrNoCredentials)
+			return
+		}
+		if !principal.HasRole(role) {
+			writeError(w, r, fmt.Errorf("%w: requires the %s role", ErrForbidden, role))
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	authn := auth.NewAPIKeyAuthenticator(map[string]auth.Principal{
+		"admin-key":  {ID: "alice", Roles: []string{auth.RoleAdmin}},
+		"writer-key": {ID: "bob"},
+	})
+	requireAuth := AuthMiddleware(authn)
+	optionalAuth := OptionalAuthMiddleware(authn)
+
+	// the wrapped handler echoes who it saw in the context
+	var seen string
+	protected := func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			seen = principal.ID
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		key        string
+		wantStatus int
+		wantSeen   string
+	}{
+		{name: "valid key", handler: requireAuth(protected), key: "writer-key", wantStatus: http.StatusNoContent, wantSeen: "bob"},
+		{name: "missing key", handler: requireAuth(protected), wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", handler: requireAuth(protected), key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "admin route with admin", handler: requireAuth(RequireRole(auth.RoleAdmin, protected)), key: "admin-key", wantStatus: http.StatusNoContent, wantSeen: "alice"},
+		{name: "admin route without admin", handler: requireAuth(RequireRole(auth.RoleAdmin, protected)), key: "writer-key", wantStatus: http.StatusForbidden},
+		{name: "optional auth without key", handler: optionalAuth(protected), wantStatus: http.StatusNoContent},
+		{name: "optional auth with key", handler: optionalAuth(protected), key: "writer-key", wantStatus: http.StatusNoContent, wantSeen: "bob"},
+		{name: "optional auth with a bad key", handler: optionalAuth(protected), key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "role check without auth", handler: RequireRole(auth.RoleAdmin, protected), key: "admin-key", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			seen = ""
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			if tc.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tc.key)
+			}
+			w := httptest.NewRecorder()
+			tc.handler(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+			if seen != tc.wantSeen {
+				t.Errorf("expected handler to see %q, got %q", tc.wantSeen, seen)
+			}
+			if tc.wantStatus == http.StatusUnauthorized {
+				decodeProblem(t, w, tc.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type CreateAuthorRequest struct {
+	Name string `json:"name"`
+	Bio  string `json:"bio"`
+}
+
+type AuthorResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Bio       string `json:"bio"`
+	CreatedAt string `json:"created_at"`
+}
+
+func newAuthorResponse(author *models.Author) AuthorResponse {
+	return AuthorResponse{
+		ID:        author.ID,
+		Name:      author.Name,
+		Bio:       author.Bio,
+		CreatedAt: author.CreatedAt.Format(timeFormat),
+	}
+}
+
+type AuthorHandler struct {
+	Service *service.AuthorServiceRepository
+	Posts   *service.PostServiceRepository
+}
+
+func NewAuthorHandler(authors *service.AuthorServiceRepository, posts *service.PostServiceRepository) *AuthorHandler {
+	return &AuthorHandler{
+		Service: authors,
+		Posts:   posts,
+	}
+}
+
+// CreateAuthor handles POST /authors, it creates the profile of the authenticated caller
+func (h *AuthorHandler) CreateAuthor(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, r, auth.ErrNoCredentials)
+		return
+	}
+
+	// Parse request
+	var req CreateAuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+
+	// Call service
+	author, err := h.Service.CreateAuthor(r.Context(), principal.ID, req.Name, req.Bio)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/authors/"+author.ID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newAuthorResponse(author))
+}
+
+// GetAuthorByID handles GET /authors/{id}
+func (h *AuthorHandler) GetAuthorByID(w http.ResponseWriter, r *http.Request) {
+	author, err := h.Service.GetAuthorByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newAuthorResponse(author))
+}
+
+// ListAuthors handles GET /authors
+func (h *AuthorHandler) ListAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.Service.ListAuthors(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []AuthorResponse{}
+	for _, author := range authors {
+		response = append(response, newAuthorResponse(author))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetAuthorPosts handles GET /authors/{id}/posts, it takes the same query params as GET /posts
+func (h *AuthorHandler) GetAuthorPosts(w http.ResponseWriter, r *http.Request) {
+	author, err := h.Service.GetAuthorByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	query, err := parsePostQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query.AuthorID = author.ID
+	applyVisibility(r, &query)
+
+	page, err := h.Posts.ListPosts(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []CreatePostResponse{}
+	for _, post := range page.Posts {
+		response = append(response, newPostResponse(post))
+	}
+
+	setNextLink(w, r, query.Offset, page)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+)
+
+// withPrincipal fakes what AuthMiddleware does for handler level tests
+func withPrincipal(req *http.Request, id string) *http.Request {
+	return req.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{ID: id}))
+}
+
+func TestAuthorHandler(t *testing.T) {
+	// setup
+	postService := service.NewPostService(store.NewInMemoryStore())
+	authorService := service.NewAuthorService(store.NewInMemoryAuthorStore())
+	authorHandler := NewAuthorHandler(authorService, postService)
+	postHandler := NewPostHandler(postService)
+
+	t.Run("create profile for the caller", func(t *testing.T) {
+		req := withPrincipal(httptest.NewRequest(http.MethodPost, "/authors", strings.NewReader(`{"name": "Alice", "bio": "Gopher"}`)), "alice")
+		w := httptest.NewRecorder()
+		authorHandler.CreateAuthor(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d", http.StatusCreated, w.Code)
+		}
+		var resp AuthorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.ID != "alice" || resp.Name != "Alice" {
+			t.Errorf("unexpected author %+v", resp)
+		}
+	})
+
+	createTests := []struct {
+		name       string
+		principal  string
+		body       string
+		wantStatus int
+	}{
+		{name: "second profile", principal: "alice", body: `{"name": "Alice 2"}`, wantStatus: http.StatusConflict},
+		{name: "blank name", principal: "bob", body: `{"name": " "}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "bad json", principal: "bob", body: `{"name":`, wantStatus: http.StatusBadRequest},
+		{name: "anonymous", body: `{"name": "Nobody"}`, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tc := range createTests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/authors", strings.NewReader(tc.body))
+			if tc.principal != "" {
+				req = withPrincipal(req, tc.principal)
+			}
+			w := httptest.NewRecorder()
+			authorHandler.CreateAuthor(w, req)
+			decodeProblem(t, w, tc.wantStatus)
+		})
+	}
+
+	t.Run("posts are linked to the authenticated author", func(t *testing.T) {
+		for _, body := range []string{`{"name": "First", "content": "Alice writes"}`, `{"name": "Second", "content": "Alice again"}`} {
+			req := withPrincipal(httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body)), "alice")
+			w := httptest.NewRecorder()
+			postHandler.CreatePost(w, req)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected status code %d but got %d", http.StatusCreated, w.Code)
+			}
+			var resp CreatePostResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.AuthorID != "alice" {
+				t.Errorf("expected author_id alice, got %q", resp.AuthorID)
+			}
+		}
+		// someone else's post must not show up on alice's page
+		if _, err := postService.CreatePost(t.Context(), "Third", "Bob writes", service.WithAuthor("bob")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("author page lists only their posts", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/authors/alice/posts?limit=1&sort=name", nil)
+		req.SetPathValue("id", "alice")
+		w := httptest.NewRecorder()
+		authorHandler.GetAuthorPosts(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+		}
+		var resp []CreatePostResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 1 || resp[0].Name != "First" {
+			t.Errorf("unexpected page %+v", resp)
+		}
+		if !strings.Contains(w.Header().Get("Link"), "offset=1") {
+			t.Errorf("expected a next link, got %q", w.Header().Get("Link"))
+		}
+	})
+
+	getTests := []struct {
+		name       string
+		id         string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{name: "get author", id: "alice", handler: authorHandler.GetAuthorByID, wantStatus: http.StatusOK},
+		{name: "get unknown author", id: "nobody", handler: authorHandler.GetAuthorByID, wantStatus: http.StatusNotFound},
+		{name: "posts of unknown author", id: "nobody", handler: authorHandler.GetAuthorPosts, wantStatus: http.StatusNotFound},
+	}
+	for _, tc := range getTests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/authors/"+tc.id, nil)
+			req.SetPathValue("id", tc.id)
+			w := httptest.NewRecorder()
+			tc.handler(w, req)
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+		})
+	}
+
+	t.Run("list authors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/authors", nil)
+		w := httptest.NewRecorder()
+		authorHandler.ListAuthors(w, req)
+
+		var resp []AuthorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || len(resp) != 1 {
+			t.Errorf("expected one author, got %d %+v", w.Code, resp)
+		}
+	})
+}
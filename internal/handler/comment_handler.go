@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type CreateCommentRequest struct {
+	Body     string `json:"body"`
+	ParentID string `json:"parent_id"`
+}
+
+// CommentResponse nests replies so GET returns whole threads
+type CommentResponse struct {
+	ID        string            `json:"id"`
+	PostID    string            `json:"post_id"`
+	ParentID  string            `json:"parent_id,omitempty"`
+	AuthorID  string            `json:"author_id"`
+	Body      string            `json:"body"`
+	CreatedAt string            `json:"created_at"`
+	Replies   []CommentResponse `json:"replies"`
+}
+
+func newCommentResponse(comment *models.Comment) CommentResponse {
+	return CommentResponse{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt.Format(timeFormat),
+		Replies:   []CommentResponse{},
+	}
+}
+
+func newThreadResponse(thread *service.CommentThread) CommentResponse {
+	response := newCommentResponse(thread.Comment)
+	for _, reply := range thread.Replies {
+		response.Replies = append(response.Replies, newThreadResponse(reply))
+	}
+	return response
+}
+
+type CommentHandler struct {
+	Service *service.CommentServiceRepository
+}
+
+func NewCommentHandler(service *service.CommentServiceRepository) *CommentHandler {
+	return &CommentHandler{
+		Service: service,
+	}
+}
+
+// CreateComment handles POST /posts/{id}/comments
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, r, auth.ErrNoCredentials)
+		return
+	}
+
+	// nobody comments on a post they can't read, not even to find out it exists
+	if err := h.checkVisible(r, r.PathValue("id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Parse request
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+
+	// Call service
+	comment, err := h.Service.CreateComment(r.Context(), r.PathValue("id"), req.ParentID, principal.ID, req.Body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newCommentResponse(comment))
+}
+
+// GetComments handles GET /posts/{id}/comments, replies are nested under their parent
+// Comments on a post the caller may not see are hidden along with the post.
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	if err := h.checkVisible(r, r.PathValue("id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	threads, err := h.Service.ListThreads(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []CommentResponse{}
+	for _, thread := range threads {
+		response = append(response, newThreadResponse(thread))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkVisible returns store.ErrNotFound for posts that don't exist and for posts the caller may not see
+func (h *CommentHandler) checkVisible(r *http.Request, id string) error {
+	post, err := h.Service.GetPost(r.Context(), id)
+	return visibleOrNotFound(r, post, err)
+}
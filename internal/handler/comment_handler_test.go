@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestCommentHandler(t *testing.T) {
+	// setup
+	postStore := store.NewInMemoryStore()
+	commentStore := store.NewInMemoryCommentStore()
+	postService := service.NewPostService(postStore, service.WithCommentStore(commentStore))
+	handler := NewCommentHandler(service.NewCommentService(commentStore, postStore))
+
+	post, err := postService.CreatePost(t.Context(), "Post with comments", "Discuss below")
+	if err != nil {
+		t.Fatalf("Error creating posts")
+	}
+
+	postComment := func(principal, postID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/posts/"+postID+"/comments", strings.NewReader(body))
+		if principal != "" {
+			req = withPrincipal(req, principal)
+		}
+		req.SetPathValue("id", postID)
+		w := httptest.NewRecorder()
+		handler.CreateComment(w, req)
+		return w
+	}
+
+	w := postComment("alice", post.ID, `{"body": "Top level comment"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d but got %d", http.StatusCreated, w.Code)
+	}
+	var top CommentResponse
+	json.Unmarshal(w.Body.Bytes(), &top)
+	if top.AuthorID != "alice" || top.PostID != post.ID {
+		t.Errorf("unexpected comment %+v", top)
+	}
+
+	if w := postComment("bob", post.ID, `{"body": "A reply", "parent_id": "`+top.ID+`"}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d but got %d", http.StatusCreated, w.Code)
+	}
+
+	errorTests := []struct {
+		name       string
+		principal  string
+		postID     string
+		body       string
+		wantStatus int
+	}{
+		{name: "anonymous", postID: post.ID, body: `{"body": "Hello there"}`, wantStatus: http.StatusUnauthorized},
+		{name: "unknown post", principal: "bob", postID: "nope", body: `{"body": "Hello there"}`, wantStatus: http.StatusNotFound},
+		{name: "empty body", principal: "bob", postID: post.ID, body: `{"body": ""}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "duplicate", principal: "alice", postID: post.ID, body: `{"body": "Top level comment"}`, wantStatus: http.StatusConflict},
+		{name: "bad parent", principal: "bob", postID: post.ID, body: `{"body": "Hello there", "parent_id": "nope"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "bad json", principal: "bob", postID: post.ID, body: `{"body":`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range errorTests {
+		t.Run(tc.name, func(t *testing.T) {
+			decodeProblem(t, postComment(tc.principal, tc.postID, tc.body), tc.wantStatus)
+		})
+	}
+
+	t.Run("threads are nested", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts/"+post.ID+"/comments", nil)
+		req.SetPathValue("id", post.ID)
+		w := httptest.NewRecorder()
+		handler.GetComments(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+		}
+		var threads []CommentResponse
+		json.Unmarshal(w.Body.Bytes(), &threads)
+		if len(threads) != 1 || len(threads[0].Replies) != 1 || threads[0].Replies[0].Body != "A reply" {
+			t.Errorf("unexpected threads %+v", threads)
+		}
+	})
+
+	t.Run("comments of unknown post", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts/nope/comments", nil)
+		req.SetPathValue("id", "nope")
+		w := httptest.NewRecorder()
+		handler.GetComments(w, req)
+		decodeProblem(t, w, http.StatusNotFound)
+	})
+
+	t.Run("a draft's comments are hidden like the draft", func(t *testing.T) {
+		draft, err := postService.CreatePost(t.Context(), "Not out yet", "Still writing", service.WithAuthor("alice"), service.WithStatus(models.StatusDraft))
+		if err != nil {
+			t.Fatalf("Error creating draft")
+		}
+		// someone else can neither comment nor learn that the draft exists
+		decodeProblem(t, postComment("bob", draft.ID, `{"body": "First!"}`), http.StatusNotFound)
+		if w := postComment("alice", draft.ID, `{"body": "Note to self"}`); w.Code != http.StatusCreated {
+			t.Fatalf("expected the author to comment, got %d", w.Code)
+		}
+
+		list := func(principal string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/posts/"+draft.ID+"/comments", nil)
+			if principal != "" {
+				req = withPrincipal(req, principal)
+			}
+			req.SetPathValue("id", draft.ID)
+			w := httptest.NewRecorder()
+			handler.GetComments(w, req)
+			return w
+		}
+		decodeProblem(t, list(""), http.StatusNotFound)
+		decodeProblem(t, list("bob"), http.StatusNotFound)
+		if w := list("alice"); w.Code != http.StatusOK {
+			t.Errorf("expected the author to read the comments, got %d", w.Code)
+		}
+	})
+}
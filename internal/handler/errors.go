@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/diff"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// Errors raised by the handlers themselves, before the service is ever called
+var (
+	ErrInvalidJSON          = errors.New("request body is not valid JSON")
+	ErrInvalidParameter     = errors.New("invalid parameter")
+	ErrInvalidRequest       = errors.New("invalid request")
+	ErrConfirmationRequired = errors.New("deleting every post requires ?confirm=true")
+)
+
+// ProblemDetails is the RFC 9457 problem+json error envelope.
+// Code is our own extension member, a stable machine readable name clients can switch on
+// instead of parsing Detail, which is meant for humans and may change.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+
+	// Errors lists every failed rule when a post breaks the validation policy
+	Errors []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem is one failed validation rule, keyed by the request field it is about
+type FieldProblem struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// problemType describes one kind of error a client can get back
+type problemType struct {
+	status int
+	code   string
+	title  string
+}
+
+// problemTypes is the central error -> HTTP mapping, checked in order with errors.Is
+var problemTypes = []struct {
+	err error
+	problemType
+}{
+	{ErrInvalidJSON, problemType{http.StatusBadRequest, "invalid_json", "Malformed JSON body"}},
+	{ErrInvalidParameter, problemType{http.StatusBadRequest, "invalid_parameter", "Invalid parameter"}},
+	{ErrInvalidRequest, problemType{http.StatusBadRequest, "invalid_request", "Invalid request"}},
+	{ErrConfirmationRequired, problemType{http.StatusPreconditionRequired, "confirmation_required", "Confirmation required"}},
+	{ErrForbidden, problemType{http.StatusForbidden, "forbidden", "Forbidden"}},
+
+	{auth.ErrNoCredentials, problemType{http.StatusUnauthorized, "unauthorized", "Authentication required"}},
+	{auth.ErrInvalidCredentials, problemType{http.StatusUnauthorized, "unauthorized", "Authentication required"}},
+
+	{models.ErrEmtpyTitle, problemType{http.StatusUnprocessableEntity, "empty_title", "Title is required"}},
+	{models.ErrEmtpyContent, problemType{http.StatusUnprocessableEntity, "empty_content", "Content is required"}},
+	{models.ErrEmptyAuthorID, problemType{http.StatusUnprocessableEntity, "empty_author_id", "Author id is required"}},
+	{models.ErrEmptyAuthorName, problemType{http.StatusUnprocessableEntity, "empty_author_name", "Author name is required"}},
+	{models.ErrEmptyComment, problemType{http.StatusUnprocessableEntity, "empty_comment", "Comment is required"}},
+	{models.ErrInvalidStatus, problemType{http.StatusUnprocessableEntity, "invalid_status", "Invalid status"}},
+	{models.ErrInvalidLimit, problemType{http.StatusBadRequest, "invalid_parameter", "Invalid parameter"}},
+	{models.ErrInvalidSort, problemType{http.StatusBadRequest, "invalid_parameter", "Invalid parameter"}},
+	{models.ErrInvalidRange, problemType{http.StatusBadRequest, "invalid_parameter", "Invalid parameter"}},
+
+	{service.ErrContentTooShort, problemType{http.StatusUnprocessableEntity, "content_too_short", "Content is too short"}},
+	{service.ErrContentTooLong, problemType{http.StatusUnprocessableEntity, "content_too_long", "Content is too long"}},
+	{service.ErrTitleTooShort, problemType{http.StatusUnprocessableEntity, "title_too_short", "Title is too short"}},
+	{service.ErrTitleTooLong, problemType{http.StatusUnprocessableEntity, "title_too_long", "Title is too long"}},
+	{service.ErrInvalidTitleChar, problemType{http.StatusUnprocessableEntity, "invalid_title_char", "Title has characters that are not allowed"}},
+	{service.ErrBannedWord, problemType{http.StatusUnprocessableEntity, "banned_word", "Banned word"}},
+	{service.ErrInvalidTag, problemType{http.StatusUnprocessableEntity, "invalid_tag", "Invalid tag"}},
+	{service.ErrTooManyTags, problemType{http.StatusUnprocessableEntity, "too_many_tags", "Too many tags"}},
+	{service.ErrInvalidCategory, problemType{http.StatusUnprocessableEntity, "invalid_category", "Invalid category"}},
+	{service.ErrMissingPublishAt, problemType{http.StatusUnprocessableEntity, "missing_publish_at", "Publish time required"}},
+	{service.ErrDuplicateTitle, problemType{http.StatusConflict, "duplicate_title", "Title already in use"}},
+	{service.ErrCommentTooShort, problemType{http.StatusUnprocessableEntity, "comment_too_short", "Comment is too short"}},
+	{service.ErrDuplicateComment, problemType{http.StatusConflict, "duplicate_comment", "Comment already posted"}},
+	{service.ErrInvalidParent, problemType{http.StatusUnprocessableEntity, "invalid_parent", "Invalid parent comment"}},
+	{service.ErrEmptyQuery, problemType{http.StatusBadRequest, "empty_query", "Search query is required"}},
+	{service.ErrNoRevisions, problemType{http.StatusNotImplemented, "no_revisions", "Revision history is not kept"}},
+	{diff.ErrTooLarge, problemType{http.StatusUnprocessableEntity, "diff_too_large", "Revisions are too different to compare"}},
+
+	{store.ErrNotFound, problemType{http.StatusNotFound, "not_found", "Resource not found"}},
+
+	// the request ran out of time, or the client went away, before the store finished
+	{context.DeadlineExceeded, problemType{http.StatusServiceUnavailable, "timeout", "Request timed out"}},
+	{context.Canceled, problemType{http.StatusServiceUnavailable, "canceled", "Request canceled"}},
+	{store.ErrAlreadyExists, problemType{http.StatusConflict, "already_exists", "Resource already exists"}},
+}
+
+var (
+	internalProblem   = problemType{http.StatusInternalServerError, "internal_error", "Internal server error"}
+	validationProblem = problemType{http.StatusUnprocessableEntity, "validation_failed", "Validation failed"}
+)
+
+// problemFor finds the mapping for err, anything unknown is a 500
+func problemFor(err error) problemType {
+	for _, p := range problemTypes {
+		if errors.Is(err, p.err) {
+			return p.problemType
+		}
+	}
+	return internalProblem
+}
+
+// writeError sends err to the client as application/problem+json.
+// Unknown errors are logged and replaced with a generic message so internals don't leak.
+// Validation failures list every broken rule in Errors, a single one keeps its own type and status.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	p := problemFor(err)
+
+	var fields []FieldProblem
+	var invalid service.ValidationErrors
+	if errors.As(err, &invalid) {
+		for _, fe := range invalid {
+			fields = append(fields, FieldProblem{Field: fe.Field, Code: problemFor(fe.Err).code, Detail: fe.Err.Error()})
+		}
+		if len(invalid) > 1 {
+			p = validationProblem
+		}
+	}
+
+	detail := err.Error()
+	if p == internalProblem {
+		slog.ErrorContext(r.Context(), "internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+		detail = ""
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.status)
+	json.NewEncoder(w).Encode(ProblemDetails{
+		Type:     "/problems/" + p.code,
+		Title:    p.title,
+		Status:   p.status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     p.code,
+		Errors:   fields,
+	})
+}
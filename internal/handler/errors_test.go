@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{name: "empty title", err: models.ErrEmtpyTitle, wantStatus: http.StatusUnprocessableEntity, wantCode: "empty_title"},
+		{name: "empty author id", err: models.ErrEmptyAuthorID, wantStatus: http.StatusUnprocessableEntity, wantCode: "empty_author_id"},
+		{name: "content too short", err: service.ErrContentTooShort, wantStatus: http.StatusUnprocessableEntity, wantCode: "content_too_short"},
+		{name: "duplicate title", err: service.ErrDuplicateTitle, wantStatus: http.StatusConflict, wantCode: "duplicate_title"},
+		{name: "no revision history", err: service.ErrNoRevisions, wantStatus: http.StatusNotImplemented, wantCode: "no_revisions"},
+		{name: "not found", err: store.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "request timed out", err: fmt.Errorf("list posts: %w", context.DeadlineExceeded), wantStatus: http.StatusServiceUnavailable, wantCode: "timeout"},
+		{
+			name:       "wrapped errors keep their mapping and detail",
+			err:        fmt.Errorf("%w: limit %q", ErrInvalidParameter, "abc"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_parameter",
+			wantDetail: `invalid parameter: limit "abc"`,
+		},
+		{
+			name:       "unknown errors are hidden",
+			err:        errors.New("pq: connection refused on 10.0.0.5"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantDetail: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts/123", nil)
+			w := httptest.NewRecorder()
+			writeError(w, req, tc.err)
+
+			problem := decodeProblem(t, w, tc.wantStatus)
+			if problem.Code != tc.wantCode {
+				t.Errorf("expected code %q got %q", tc.wantCode, problem.Code)
+			}
+			if problem.Status != tc.wantStatus || problem.Instance != "/posts/123" || problem.Type != "/problems/"+tc.wantCode {
+				t.Errorf("unexpected envelope %+v", problem)
+			}
+
+			wantDetail := tc.wantDetail
+			if wantDetail == "" && tc.wantStatus != http.StatusInternalServerError {
+				wantDetail = tc.err.Error()
+			}
+			if problem.Detail != wantDetail {
+				t.Errorf("expected detail %q got %q", wantDetail, problem.Detail)
+			}
+		})
+	}
+}
+
+func TestWriteError_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantFields []FieldProblem
+	}{
+		{
+			name:       "a single failure keeps its own type",
+			err:        service.ValidationErrors{{Field: "name", Err: service.ErrDuplicateTitle}},
+			wantStatus: http.StatusConflict,
+			wantCode:   "duplicate_title",
+			wantFields: []FieldProblem{{Field: "name", Code: "duplicate_title", Detail: service.ErrDuplicateTitle.Error()}},
+		},
+		{
+			name: "several failures are listed by field",
+			err: service.ValidationErrors{
+				{Field: "name", Err: service.ErrTitleTooLong},
+				{Field: "content", Err: fmt.Errorf("%w: %q", service.ErrBannedWord, "spam")},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "validation_failed",
+			wantFields: []FieldProblem{
+				{Field: "name", Code: "title_too_long", Detail: "title is too long"},
+				{Field: "content", Code: "banned_word", Detail: `contains a banned word: "spam"`},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			w := httptest.NewRecorder()
+			writeError(w, req, tc.err)
+
+			problem := decodeProblem(t, w, tc.wantStatus)
+			if problem.Code != tc.wantCode {
+				t.Errorf("expected code %q got %q", tc.wantCode, problem.Code)
+			}
+			if !slices.Equal(problem.Errors, tc.wantFields) {
+				t.Errorf("expected errors %+v got %+v", tc.wantFields, problem.Errors)
+			}
+		})
+	}
+}
+
+// decodeProblem checks the status and content type and returns the problem+json body
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder, wantStatus int) ProblemDetails {
+	t.Helper()
+
+	if w.Code != wantStatus {
+		t.Fatalf("expected status code %d but got %d", wantStatus, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected problem+json, got %q", ct)
+	}
+
+	var problem ProblemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("unmarshal problem: %v", err)
+	}
+	return problem
+}
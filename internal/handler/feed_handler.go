@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/feed"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/internal/web"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// FeedSize is how many of the newest posts a feed carries
+const FeedSize = 20
+
+// FeedFormat picks the document type a feed route serves
+type FeedFormat int
+
+const (
+	RSS FeedFormat = iota
+	Atom
+)
+
+// FeedHandler serves the site wide feed plus one per author and per tag, in RSS 2.0 and Atom.
+// Responses carry Last-Modified and an ETag so pollers get a 304 when nothing changed.
+type FeedHandler struct {
+	Posts   *service.PostServiceRepository
+	Authors *service.AuthorServiceRepository
+	Title   string
+	// BaseURL prefixes every link in a feed. It comes from the config and never from the
+	// request: feeds are cached publicly, a forged Host header would poison them for everyone.
+	BaseURL string
+}
+
+// NewFeedHandler serves feeds linking to baseURL, e.g. "https://blog.example.com"
+func NewFeedHandler(posts *service.PostServiceRepository, authors *service.AuthorServiceRepository, baseURL string) *FeedHandler {
+	return &FeedHandler{
+		Posts:   posts,
+		Authors: authors,
+		Title:   "goblog",
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Site handles GET /feed.rss and GET /feed.atom
+func (h *FeedHandler) Site(format FeedFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, format, models.PostQuery{}, h.Title, "/")
+	}
+}
+
+// Author handles GET /authors/{id}/feed.rss and .atom, 404 for authors without a profile
+func (h *FeedHandler) Author(format FeedFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		author, err := h.Authors.GetAuthorByID(r.Context(), r.PathValue("id"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		h.serve(w, r, format, models.PostQuery{AuthorID: author.ID}, h.Title+" - "+author.Name, "/")
+	}
+}
+
+// Tag handles GET /tags/{tag}/feed.rss and .atom, an unused tag is an empty feed
+func (h *FeedHandler) Tag(format FeedFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tag := service.NormalizeFilter(r.PathValue("tag"))
+		h.serve(w, r, format, models.PostQuery{Tag: tag}, h.Title+" - #"+tag, "/?"+url.Values{"tag": {tag}}.Encode())
+	}
+}
+
+// authorName is the display name from the author's profile, empty when there is none
+// so the feed falls back to its own title rather than showing an internal ID
+func (h *FeedHandler) authorName(ctx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	author, err := h.Authors.GetAuthorByID(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return author.Name, nil
+}
+
+func (h *FeedHandler) serve(w http.ResponseWriter, r *http.Request, format FeedFormat, query models.PostQuery, title, page string) {
+	query.Limit = FeedSize
+	query.SortBy = models.SortByCreatedAt
+	query.Descending = true
+	query.PublicOnly = true // feeds are read anonymously
+
+	posts, err := h.Posts.ListPosts(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	base := h.BaseURL
+	f := feed.Feed{
+		Title:       title,
+		Description: "Latest posts from " + title,
+		Link:        base + page,
+		Self:        base + r.URL.Path,
+	}
+	// authors are looked up once per feed, most feeds are written by a handful of people
+	names := map[string]string{}
+	for _, post := range posts.Posts {
+		name, ok := names[post.AuthorID]
+		if !ok {
+			if name, err = h.authorName(r.Context(), post.AuthorID); err != nil {
+				writeError(w, r, err)
+				return
+			}
+			names[post.AuthorID] = name
+		}
+
+		// a scheduled post goes out at PublishAt, which can be well after it was written or last edited
+		published := post.PublishAt
+		if published.IsZero() {
+			published = post.CreatedAt
+		}
+		updated := post.UpdatedAt
+		if published.After(updated) {
+			updated = published
+		}
+
+		f.Items = append(f.Items, feed.Item{
+			// the id link keeps working through renames, readers would see a new entry if it changed
+			ID:          base + "/p/" + post.ID,
+			Title:       post.Name,
+			Link:        base + web.PostURL(post),
+			Author:      name,
+			Categories:  post.Tags,
+			ContentHTML: post.ContentHTML,
+			Published:   published,
+			Updated:     updated,
+		})
+		// an edit to an older post changes the feed too, so it's the newest update that counts
+		if updated.After(f.Updated) {
+			f.Updated = updated
+		}
+	}
+
+	var body []byte
+	switch format {
+	case Atom:
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		body, err = f.Atom()
+	default:
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		body, err = f.RSS()
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// the ETag covers what Last-Modified can't see, like a post being deleted
+	sum := sha256.Sum256(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	// ServeContent answers If-None-Match and If-Modified-Since with 304 for us
+	http.ServeContent(w, r, "", f.Updated.Truncate(time.Second), bytes.NewReader(body))
+}
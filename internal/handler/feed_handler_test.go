@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestFeedHandler(t *testing.T) {
+	// setup
+	postStore := store.NewInMemoryStore()
+	postService := service.NewPostService(postStore)
+	authorService := service.NewAuthorService(store.NewInMemoryAuthorStore())
+	feedHandler := NewFeedHandler(postService, authorService, "https://blog.example.com/")
+
+	authorService.CreateAuthor(t.Context(), "alice", "Alice", "")
+	updated := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Older", "Newer"} {
+		post, _ := models.NewPost(name, "Some <b>content</b> & more")
+		post.AuthorID = "alice"
+		post.Tags = []string{"go"}
+		post.CreatedAt = updated.Add(time.Duration(i-2) * time.Hour)
+		post.UpdatedAt = post.CreatedAt
+		post.PublishAt = post.CreatedAt
+		postStore.Create(t.Context(), post)
+	}
+	edited, _ := models.NewPost("Edited", "Untagged and edited later")
+	edited.CreatedAt = updated.Add(-time.Hour * 24)
+	edited.UpdatedAt = updated
+	// scheduled: written first, edited, then published a little later
+	edited.PublishAt = updated.Add(-time.Hour * 12)
+	postStore.Create(t.Context(), edited)
+
+	get := func(serve http.HandlerFunc, target string, pathValues map[string]string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range pathValues {
+			req.SetPathValue(k, v)
+		}
+		for k := range header {
+			req.Header.Set(k, header.Get(k))
+		}
+		w := httptest.NewRecorder()
+		serve(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name        string
+		serve       http.HandlerFunc
+		target      string
+		pathValues  map[string]string
+		wantStatus  int
+		wantType    string
+		wantEntries int
+	}{
+		{name: "site rss", serve: feedHandler.Site(RSS), target: "/feed.rss", wantStatus: http.StatusOK, wantType: "application/rss+xml", wantEntries: 3},
+		{name: "site atom", serve: feedHandler.Site(Atom), target: "/feed.atom", wantStatus: http.StatusOK, wantType: "application/atom+xml", wantEntries: 3},
+		{name: "author feed", serve: feedHandler.Author(Atom), target: "/authors/alice/feed.atom", pathValues: map[string]string{"id": "alice"}, wantStatus: http.StatusOK, wantType: "application/atom+xml", wantEntries: 2},
+		{name: "unknown author", serve: feedHandler.Author(RSS), target: "/authors/bob/feed.rss", pathValues: map[string]string{"id": "bob"}, wantStatus: http.StatusNotFound},
+		{name: "tag feed", serve: feedHandler.Tag(RSS), target: "/tags/Go/feed.rss", pathValues: map[string]string{"tag": "Go"}, wantStatus: http.StatusOK, wantType: "application/rss+xml", wantEntries: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := get(tc.serve, tc.target, tc.pathValues, nil)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if !strings.HasPrefix(w.Header().Get("Content-Type"), tc.wantType) {
+				t.Errorf("expected content type %s, got %s", tc.wantType, w.Header().Get("Content-Type"))
+			}
+			body := w.Body.String()
+			if entries := strings.Count(body, "<item>") + strings.Count(body, "<entry>"); entries != tc.wantEntries {
+				t.Errorf("expected %d entries, got %d", tc.wantEntries, entries)
+			}
+			if strings.Contains(body, "<b>") {
+				t.Errorf("content is not escaped:\n%s", body)
+			}
+		})
+	}
+
+	t.Run("newest first", func(t *testing.T) {
+		body := get(feedHandler.Site(RSS), "/feed.rss", nil, nil).Body.String()
+		if strings.Index(body, "Newer") > strings.Index(body, "Older") {
+			t.Errorf("expected Newer before Older:\n%s", body)
+		}
+	})
+
+	t.Run("items carry the author's name and publish time", func(t *testing.T) {
+		body := get(feedHandler.Site(Atom), "/feed.atom", nil, nil).Body.String()
+		if !strings.Contains(body, "<name>Alice</name>") || strings.Contains(body, "<name>alice</name>") {
+			t.Errorf("expected the author's display name, not the id:\n%s", body)
+		}
+		if want := "<published>" + edited.PublishAt.Format(time.RFC3339) + "</published>"; !strings.Contains(body, want) {
+			t.Errorf("expected %s for the scheduled post:\n%s", want, body)
+		}
+	})
+
+	t.Run("links use the configured base URL, not the Host header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/feed.atom", nil)
+		req.Host = "attacker.example"
+		w := httptest.NewRecorder()
+		feedHandler.Site(Atom)(w, req)
+
+		body := w.Body.String()
+		if strings.Contains(body, "attacker.example") {
+			t.Errorf("the Host header leaked into the feed:\n%s", body)
+		}
+		if !strings.Contains(body, `href="https://blog.example.com/feed.atom"`) {
+			t.Errorf("expected links under https://blog.example.com:\n%s", body)
+		}
+	})
+
+	t.Run("conditional requests", func(t *testing.T) {
+		first := get(feedHandler.Site(RSS), "/feed.rss", nil, nil)
+		etag := first.Header().Get("ETag")
+		lastModified := first.Header().Get("Last-Modified")
+
+		// the edited post is older but was updated last
+		if lastModified != updated.Format(http.TimeFormat) {
+			t.Errorf("expected Last-Modified %s, got %s", updated.Format(http.TimeFormat), lastModified)
+		}
+		if etag == "" {
+			t.Fatal("expected an ETag")
+		}
+
+		if w := get(feedHandler.Site(RSS), "/feed.rss", nil, http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified {
+			t.Errorf("If-None-Match: expected %d, got %d", http.StatusNotModified, w.Code)
+		}
+		if w := get(feedHandler.Site(RSS), "/feed.rss", nil, http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusNotModified {
+			t.Errorf("If-Modified-Since: expected %d, got %d", http.StatusNotModified, w.Code)
+		}
+
+		// deleting a post doesn't move Last-Modified, the ETag still has to change
+		postService.DeletePost(t.Context(), edited.ID)
+		if w := get(feedHandler.Site(RSS), "/feed.rss", nil, http.Header{"If-None-Match": {etag}}); w.Code != http.StatusOK {
+			t.Errorf("expected a fresh feed after a delete, got %d", w.Code)
+		}
+	})
+}
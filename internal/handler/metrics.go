@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests no route matched, so scanners can't blow up the number of series
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics are the request counters and latency histograms MetricsMiddleware fills in
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics registers goblog_http_requests_total and goblog_http_request_duration_seconds in reg
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goblog_http_requests_total",
+			Help: "HTTP requests by route and status code.",
+		}, []string{"route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "goblog_http_request_duration_seconds",
+			Help:    "HTTP request latency by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// MetricsMiddleware counts requests and times them per route.
+// The route is the mux pattern, e.g. "GET /post/{id}", not the path, which keeps one series per
+// route instead of one per post. It has to wrap the mux itself, the mux only fills in
+// r.Pattern once it has matched the request:
+//
+//	withMetrics := handler.MetricsMiddleware(handler.NewHTTPMetrics(registry))
+//	srv.Handler = withMetrics(mux.ServeHTTP)
+func MetricsMiddleware(m *HTTPMetrics) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// same status capturing wrapper as LoggingMiddleware
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+
+			route := r.Pattern
+			if route == "" {
+				route = unmatchedRoute
+			}
+			m.requests.WithLabelValues(route, strconv.Itoa(wrapped.statusCode)).Inc()
+			m.duration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+		}
+	}
+}
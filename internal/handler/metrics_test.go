@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m := NewHTTPMetrics(reg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /post/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	withMetrics := MetricsMiddleware(m)(mux.ServeHTTP)
+
+	for _, path := range []string{"/post/1", "/post/2", "/post/missing", "/wp-login.php"} {
+		withMetrics(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tests := []struct {
+		route, code string
+		want        float64
+	}{
+		{"GET /post/{id}", "200", 2},
+		{"GET /post/{id}", "404", 1},
+		{unmatchedRoute, "404", 1},
+	}
+	for _, tc := range tests {
+		if got := testutil.ToFloat64(m.requests.WithLabelValues(tc.route, tc.code)); got != tc.want {
+			t.Errorf("%s %s: got %v want %v", tc.route, tc.code, got, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := w.Body
+	for _, want := range []string{
+		`goblog_http_request_duration_seconds_count{route="GET /post/{id}"} 3`,
+		`goblog_http_request_duration_seconds_count{route="unmatched"} 1`,
+		`# TYPE goblog_http_request_duration_seconds histogram`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s in\n%s", want, out.String())
+		}
+	}
+}
@@ -1,37 +1,126 @@
 package handler
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
 	"time"
+
+	"github.com/aziz-shoko/goblog/internal/logging"
 )
 
+// maxRequestIDLength caps request IDs sent by clients, longer or odd looking ones are replaced
+const maxRequestIDLength = 128
+
 // LoggingMiddleware logs HTTP requests
 // Design pattern: Decorator Pattern - wraps handler with additional behavior
 // A middleware is literally "something that takes one handler and produces another handler that does extra work around it."" Hence:
 // type Middleware func(next http.Handler) http.Handler
+//
+// Every request gets an ID, the client's X-Request-ID if it sent a sane one, otherwise a new one.
+// It goes back in the response header and into the context, so the service and store lines
+// logged for the request carry the same request_id as the access line logged here.
 func LoggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		// Wrap the ResponseWriter to capture status code
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(logging.RequestIDHeader, id)
+		r = r.WithContext(logging.WithRequestID(r.Context(), id))
+
+		// Wrap the ResponseWriter to capture status code and size
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		// Call the next handler
 		next.ServeHTTP(wrapped, r)
 
 		// log the request
-		log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, time.Since(start))
+		level := slog.LevelInfo
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", wrapped.statusCode),
+			slog.Int64("bytes", wrapped.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", clientIP(r)),
+			slog.String("user_agent", r.UserAgent()),
+		}
+		// only a hint, anyone can send the header unless a proxy in front overwrites it
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			attrs = append(attrs, slog.String("forwarded_for", forwarded))
+		}
+		slog.LogAttrs(r.Context(), level, "request", attrs...)
 	})
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// validRequestID accepts short IDs made of letters, digits and a few separators,
+// anything else a client sends could mess up log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		ok := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == ':'
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// clientIP is the address the request came from, without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// TimeoutMiddleware puts a deadline on the request context. The service and the stores check the
+// context, so work still running past the deadline is cut short and the client gets a 503 instead of
+// waiting on a slow query. A client that disconnects cancels the context the same way.
+//
+//	requestTimeout := handler.TimeoutMiddleware(10 * time.Second)
+//	http.ListenAndServe(":8080", requestTimeout(mux.ServeHTTP))
+func TimeoutMiddleware(timeout time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// responseWriter wraps http.ResponseWriter to capture status code and how many body bytes were written
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int64
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the real writer, e.g. to flush
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
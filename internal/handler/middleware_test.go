@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/logging"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	postHandler := NewPostHandler(service.NewPostService(store.NewInMemoryStore()))
+
+	// stands in for a slow query, it only returns once the deadline has passed
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		postHandler.GetPostsAll(w, r)
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{name: "fast enough", handler: TimeoutMiddleware(time.Second)(postHandler.GetPostsAll), wantStatus: http.StatusOK},
+		{name: "past the deadline", handler: TimeoutMiddleware(time.Millisecond)(slow), wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+			w := httptest.NewRecorder()
+			tc.handler(w, req)
+
+			if tc.wantStatus == http.StatusOK {
+				if w.Code != tc.wantStatus {
+					t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+				}
+				return
+			}
+			if problem := decodeProblem(t, w, tc.wantStatus); problem.Code != "timeout" {
+				t.Errorf("unexpected problem %+v", problem)
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, "json", slog.LevelInfo)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logger)
+
+	// the handler logs on its own too, that line has to carry the same request_id
+	var seenID string
+	inner := func(w http.ResponseWriter, r *http.Request) {
+		seenID = logging.RequestID(r.Context())
+		slog.InfoContext(r.Context(), "inside")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}
+
+	tests := []struct {
+		name   string
+		sentID string
+		wantID func(string) bool
+	}{
+		{name: "client id is kept", sentID: "abc-123", wantID: func(id string) bool { return id == "abc-123" }},
+		{name: "missing id is generated", wantID: func(id string) bool { return len(id) == 32 }},
+		{name: "odd id is replaced", sentID: "evil\nline", wantID: func(id string) bool { return len(id) == 32 }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+			req.RemoteAddr = "192.0.2.7:51234"
+			req.Header.Set("User-Agent", "test-agent")
+			if tc.sentID != "" {
+				req.Header.Set(logging.RequestIDHeader, tc.sentID)
+			}
+			w := httptest.NewRecorder()
+			LoggingMiddleware(inner)(w, req)
+
+			id := w.Header().Get(logging.RequestIDHeader)
+			if !tc.wantID(id) || id != seenID {
+				t.Fatalf("unexpected request id %q, handler saw %q", id, seenID)
+			}
+
+			var lines []map[string]any
+			dec := json.NewDecoder(&buf)
+			for dec.More() {
+				var line map[string]any
+				if err := dec.Decode(&line); err != nil {
+					t.Fatal(err)
+				}
+				lines = append(lines, line)
+			}
+			if len(lines) != 2 {
+				t.Fatalf("expected 2 log lines, got %v", lines)
+			}
+			for _, line := range lines {
+				if line["request_id"] != id {
+					t.Errorf("expected request_id %s in %v", id, line)
+				}
+			}
+
+			access := lines[1]
+			want := map[string]any{
+				"msg": "request", "method": "GET", "path": "/posts", "status": float64(http.StatusTeapot),
+				"bytes": float64(len("short and stout")), "client_ip": "192.0.2.7", "user_agent": "test-agent",
+			}
+			for key, value := range want {
+				if access[key] != value {
+					t.Errorf("expected %s=%v, got %v", key, value, access[key])
+				}
+			}
+		})
+	}
+}
@@ -1,23 +1,92 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aziz-shoko/goblog/internal/auth"
 	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
 )
 
 type CreatePostRequest struct {
-	Name    string `json:"name"`
-	Content string `json:"content"`
+	Name     string   `json:"name"`
+	Content  string   `json:"content"`
+	Tags     []string `json:"tags"`
+	Category string   `json:"category"`
+	// Status defaults to published, a future PublishAt schedules the post
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// UpdatePostRequest uses pointers so PATCH can tell "not sent" apart from "sent empty"
+type UpdatePostRequest struct {
+	Name    *string `json:"name"`
+	Content *string `json:"content"`
 }
 
 type CreatePostResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Content     string   `json:"content"`
+	ContentHTML string   `json:"content_html"`
+	Slug        string   `json:"slug"`
+	AuthorID    string   `json:"author_id,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags"`
+	Status      string   `json:"status"`
+	PublishAt   string   `json:"publish_at,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	DeletedAt   string   `json:"deleted_at,omitempty"`
+}
+
+// SearchResultResponse is a post plus its relevance score, the post fields are inlined
+type SearchResultResponse struct {
+	CreatePostResponse
+	Score float64 `json:"score"`
+}
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+func newPostResponse(post *models.Post) CreatePostResponse {
+	// always an array in JSON, never null
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	publishAt := ""
+	if !post.PublishAt.IsZero() {
+		publishAt = post.PublishAt.Format(timeFormat)
+	}
+	deletedAt := ""
+	if !post.DeletedAt.IsZero() {
+		deletedAt = post.DeletedAt.Format(timeFormat)
+	}
+	return CreatePostResponse{
+		ID:          post.ID,
+		Name:        post.Name,
+		Content:     post.Content,
+		ContentHTML: post.ContentHTML,
+		Slug:        post.Slug,
+		AuthorID:    post.AuthorID,
+		Category:    post.Category,
+		Tags:        tags,
+		Status:      string(post.Status),
+		PublishAt:   publishAt,
+		CreatedAt:   post.CreatedAt.Format(timeFormat),
+		UpdatedAt:   post.UpdatedAt.Format(timeFormat),
+		DeletedAt:   deletedAt,
+	}
 }
 
 type PostHandler struct {
@@ -35,24 +104,31 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var req CreatePostRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, ErrInvalidJSON)
 		return
 	}
 
+	// the author is whoever is authenticated, never something the client can put in the body
+	opts := []service.PostOption{service.WithTags(req.Tags...), service.WithCategory(req.Category)}
+	if req.Status != "" {
+		opts = append(opts, service.WithStatus(models.PostStatus(req.Status)))
+	}
+	if req.PublishAt != nil {
+		opts = append(opts, service.WithPublishAt(*req.PublishAt))
+	}
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		opts = append(opts, service.WithAuthor(principal.ID))
+	}
+
 	// Call service
-	post, err := h.Service.CreatePost(req.Name, req.Content)
+	post, err := h.Service.CreatePost(r.Context(), req.Name, req.Content, opts...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	// Build response
-	response := CreatePostResponse{
-		ID:        post.ID,
-		Name:      post.Name,
-		Content:   post.Content,
-		CreatedAt: post.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}
+	response := newPostResponse(post)
 
 	// send response
 	w.Header().Set("Content-Type", "application/json")
@@ -65,42 +141,194 @@ func (h *PostHandler) GetPostByID(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/post/")
 
 	// Call service
-	post, err := h.Service.Store.GetByID(id)
+	post, err := h.Service.GetPostByID(r.Context(), id)
+	if err == nil && !canView(r, post) {
+		err = store.ErrNotFound // drafts don't exist as far as other readers are concerned
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
 	// Build response
-	response := CreatePostResponse{
-		ID:        post.ID,
-		Name:      post.Name,
-		Content:   post.Content,
-		CreatedAt: post.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}
+	response := newPostResponse(post)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetPostsAll returns all the posts
+// GetPostBySlug handles GET /post/by-slug/{slug}.
+// A slug the post had before its title was edited answers with a permanent redirect to the current one.
+func (h *PostHandler) GetPostBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	post, err := h.Service.GetPostBySlug(r.Context(), slug)
+	if err == nil && !canView(r, post) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if post.Slug != slug {
+		http.Redirect(w, r, "/post/by-slug/"+url.PathEscape(post.Slug), http.StatusMovedPermanently)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPostResponse(post))
+}
+
+// GetPostsAll returns one page of posts
+// Query params: limit, offset, sort (created_at|name), order (asc|desc), created_after, created_before (RFC 3339),
+// tag, category and status. Callers only see published posts plus their own, admins see everything.
+// The body stays a plain JSON array, the next page is advertised in a Link header (RFC 8288).
 func (h *PostHandler) GetPostsAll(w http.ResponseWriter, r *http.Request) {
+	query, err := parsePostQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	applyVisibility(r, &query)
+
 	// call service
-	posts, err := h.Service.Store.GetAll()
+	page, err := h.Service.ListPosts(r.Context(), query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
 	// Build Response
 	response := []CreatePostResponse{}
-	for _, post := range posts {
-		response = append(response, CreatePostResponse{
-			ID:        post.ID,
-			Name:      post.Name,
-			Content:   post.Content,
-			CreatedAt: post.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	for _, post := range page.Posts {
+		response = append(response, newPostResponse(post))
+	}
+
+	setNextLink(w, r, query.Offset, page)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// setNextLink advertises the next page in a Link header (RFC 8288), keeping every other query param
+func setNextLink(w http.ResponseWriter, r *http.Request, offset int, page *models.PostPage) {
+	if !page.HasMore {
+		return
+	}
+	next := r.URL.Query()
+	next.Set("offset", strconv.Itoa(offset+len(page.Posts)))
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+}
+
+// parsePage reads ?limit= and ?offset=, zero means the param was left out
+func parsePage(values url.Values) (limit, offset int, err error) {
+	if v := values.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return 0, 0, fmt.Errorf("%w: limit %q", ErrInvalidParameter, v)
+		}
+	}
+	if v := values.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("%w: offset %q", ErrInvalidParameter, v)
+		}
+	}
+	return limit, offset, nil
+}
+
+// parsePostQuery turns the GET /posts query string into a models.PostQuery.
+// Newest first is the default for dates, A to Z for names.
+func parsePostQuery(values url.Values) (models.PostQuery, error) {
+	query := models.PostQuery{
+		SortBy:   models.SortField(values.Get("sort")),
+		Tag:      service.NormalizeFilter(values.Get("tag")),
+		Category: service.NormalizeFilter(values.Get("category")),
+		Status:   models.PostStatus(values.Get("status")),
+	}
+
+	var err error
+	if query.Limit, query.Offset, err = parsePage(values); err != nil {
+		return query, err
+	}
+
+	switch values.Get("order") {
+	case "":
+		query.Descending = query.SortBy == "" || query.SortBy == models.SortByCreatedAt
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("%w: order must be asc or desc", ErrInvalidParameter)
+	}
+
+	if v := values.Get("created_after"); v != "" {
+		if query.CreatedAfter, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, fmt.Errorf("%w: created_after %q, expected RFC 3339", ErrInvalidParameter, v)
+		}
+	}
+	if v := values.Get("created_before"); v != "" {
+		if query.CreatedBefore, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, fmt.Errorf("%w: created_before %q, expected RFC 3339", ErrInvalidParameter, v)
+		}
+	}
+
+	return query, nil
+}
+
+// applyVisibility limits a listing to what the caller may read:
+// published posts plus their own drafts, scheduled and archived posts. Admins see everything.
+func applyVisibility(r *http.Request, query *models.PostQuery) {
+	principal, ok := auth.FromContext(r.Context())
+	if ok && principal.HasRole(auth.RoleAdmin) {
+		return
+	}
+	query.PublicOnly = true
+	if ok {
+		query.Viewer = principal.ID
+	}
+}
+
+// canView is applyVisibility for a single post
+func canView(r *http.Request, post *models.Post) bool {
+	return post.IsPublic() || canManage(r, post)
+}
+
+// canManage reports whether the caller wrote the post or is an admin
+func canManage(r *http.Request, post *models.Post) bool {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		return false
+	}
+	return principal.HasRole(auth.RoleAdmin) || (post.AuthorID != "" && post.AuthorID == principal.ID)
+}
+
+// SearchPosts handles GET /posts/search?q=...&limit=...
+func (h *PostHandler) SearchPosts(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			writeError(w, r, fmt.Errorf("%w: limit %q", ErrInvalidParameter, v))
+			return
+		}
+	}
+
+	// call service, hidden posts are dropped before the limit so they don't cut the page short
+	visible := func(post *models.Post) bool { return canView(r, post) }
+	results, err := h.Service.SearchPosts(r.Context(), r.URL.Query().Get("q"), limit, visible)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []SearchResultResponse{}
+	for _, result := range results {
+		response = append(response, SearchResultResponse{
+			CreatePostResponse: newPostResponse(result.Post),
+			Score:              result.Score,
 		})
 	}
 
@@ -109,13 +337,144 @@ func (h *PostHandler) GetPostsAll(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// UpdatePost handles PUT and PATCH /posts/{id}
+// PUT replaces the whole post so both fields are required, PATCH only changes the fields it sends
+func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	// Parse request
+	var req UpdatePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+
+	if r.Method == http.MethodPut && (req.Name == nil || req.Content == nil) {
+		writeError(w, r, fmt.Errorf("%w: PUT requires both name and content", ErrInvalidRequest))
+		return
+	}
+	if req.Name == nil && req.Content == nil {
+		writeError(w, r, fmt.Errorf("%w: nothing to update", ErrInvalidRequest))
+		return
+	}
+
+	if _, err := h.managedPost(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// the editor is recorded on the revision this creates
+	var editorID string
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		editorID = principal.ID
+	}
+
+	// Call service
+	post, err := h.Service.UpdatePost(r.Context(), id, editorID, req.Name, req.Content)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPostResponse(post))
+}
+
+// PublishPostRequest is the optional body of POST /posts/{id}/publish
+type PublishPostRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// PublishPost handles POST /posts/{id}/publish, a publish_at in the future schedules the post instead
+func (h *PostHandler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	// the body is optional, no body means publish right now
+	var req PublishPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+
+	h.managePost(w, r, func(ctx context.Context, id string) (*models.Post, error) {
+		var at time.Time
+		if req.PublishAt != nil {
+			at = *req.PublishAt
+		}
+		return h.Service.PublishPost(ctx, id, at)
+	})
+}
+
+// UnpublishPost handles POST /posts/{id}/unpublish, the post goes back to being a draft
+func (h *PostHandler) UnpublishPost(w http.ResponseWriter, r *http.Request) {
+	h.managePost(w, r, h.Service.UnpublishPost)
+}
+
+// ArchivePost handles POST /posts/{id}/archive
+func (h *PostHandler) ArchivePost(w http.ResponseWriter, r *http.Request) {
+	h.managePost(w, r, h.Service.ArchivePost)
+}
+
+// managedPost loads the post at {id} for a change by the caller. Posts the caller can't see are
+// not found, posts they can see but didn't write are forbidden, unless they are an admin.
+func (h *PostHandler) managedPost(r *http.Request) (*models.Post, error) {
+	post, err := h.Service.GetPostByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	if !canView(r, post) {
+		return nil, store.ErrNotFound
+	}
+	if !canManage(r, post) {
+		return nil, fmt.Errorf("%w: only the author can change this post", ErrForbidden)
+	}
+	return post, nil
+}
+
+// managePost runs a lifecycle change or a restore if the caller wrote the post or is an admin
+func (h *PostHandler) managePost(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id string) (*models.Post, error)) {
+	post, err := h.managedPost(r)
+	if err == nil {
+		post, err = change(r.Context(), post.ID)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPostResponse(post))
+}
+
+// DeletePost handles DELETE /posts/{id}, the post goes to the trash and can be restored until it is purged
+func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	post, err := h.managedPost(r)
+	if err == nil {
+		err = h.Service.DeletePost(r.Context(), post.ID)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // DeleteAllPosts handler
+// Wiping every post is too easy to trigger by accident (a DELETE meant for /posts/{id}
+// with an empty id lands here), so the caller has to ask for it explicitly with ?confirm=true
 func (h *PostHandler) DeleteAllPosts(w http.ResponseWriter, r *http.Request) {
-	// call service 
-	err := h.Service.Store.DeleteAll()
+	if r.URL.Query().Get("confirm") != "true" {
+		writeError(w, r, ErrConfirmationRequired)
+		return
+	}
+
+	// call service
+	err := h.Service.DeleteAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
@@ -10,7 +10,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/aziz-shoko/goblog/internal/auth"
 	"github.com/aziz-shoko/goblog/internal/service"
 	"github.com/aziz-shoko/goblog/internal/store"
 	"github.com/aziz-shoko/goblog/models"
@@ -31,7 +33,7 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	}{
 		{
 			name:       "success",
-			body:       CreatePostRequest{"My Name", "My content"},
+			body:       CreatePostRequest{Name: "My Name", Content: "My content"},
 			wantStatus: http.StatusCreated,
 			wantTitle:  "My Name",
 		},
@@ -43,8 +45,14 @@ func TestPostHandler_CreatePost(t *testing.T) {
 		},
 		{
 			name:       "short content",
-			body:       CreatePostRequest{"Test Name", "hi"},
-			wantStatus: http.StatusBadRequest,
+			body:       CreatePostRequest{Name: "Test Name", Content: "hi"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  true,
+		},
+		{
+			name:       "duplicate title",
+			body:       CreatePostRequest{Name: "my name", Content: "Other content"},
+			wantStatus: http.StatusConflict,
 			wantError:  true,
 		},
 	}
@@ -58,6 +66,10 @@ func TestPostHandler_CreatePost(t *testing.T) {
 				t.Errorf("expected %d, got %d", tc.wantStatus, w.Code)
 			}
 
+			if tc.wantError {
+				decodeProblem(t, w, tc.wantStatus)
+			}
+
 			if tc.wantStatus == http.StatusCreated {
 				var resp CreatePostResponse
 				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
@@ -131,7 +143,7 @@ func TestPostHandler_Get(t *testing.T) {
 	var response CreatePostResponse
 
 	// make specific post for this test
-	post, err := service.CreatePost("some test title", "some test content for this")
+	post, err := service.CreatePost(t.Context(), "some test title", "some test content for this")
 	if err != nil {
 		t.Fatalf("Error creating posts")
 	}
@@ -181,6 +193,37 @@ func TestPostHandler_Get(t *testing.T) {
 	}
 }
 
+func TestPostHandler_GetRendersMarkdown(t *testing.T) {
+	service := service.NewPostService(store.NewInMemoryStore())
+	handler := NewPostHandler(service)
+
+	post, err := service.CreatePost(t.Context(), "Markdown", "some *test* content <script>alert(1)</script>")
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/post/"+post.ID, nil)
+	req.SetPathValue("id", post.ID)
+	w := httptest.NewRecorder()
+	handler.GetPostByID(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+	}
+	var response CreatePostResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	// the source stays as written, the HTML is rendered and sanitized
+	if response.Content != post.Content {
+		t.Errorf("expected content %q got %q", post.Content, response.Content)
+	}
+	if want := "<p>some <em>test</em> content </p>\n"; response.ContentHTML != want {
+		t.Errorf("expected content_html %q got %q", want, response.ContentHTML)
+	}
+}
+
 func TestPostHandler_GetAll(t *testing.T) {
 	// setup
 	store := store.NewInMemoryStore()
@@ -190,7 +233,7 @@ func TestPostHandler_GetAll(t *testing.T) {
 	// Create some posts
 	posts := []*models.Post{}
 	for i := range 5 {
-		post, err := service.CreatePost(strconv.Itoa(i)+"Name", "Some Content"+strconv.Itoa(i))
+		post, err := service.CreatePost(t.Context(), strconv.Itoa(i)+"Name", "Some Content"+strconv.Itoa(i))
 		if err != nil {
 			t.Fatalf("Error creating posts")
 		}
@@ -227,21 +270,532 @@ func TestPostHandler_GetAll(t *testing.T) {
 		}
 	})
 
-	t.Run("Delete All Posts", func(t *testing.T) {
-		// Request 
+	t.Run("Delete All Posts needs confirmation", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodDelete, "/posts", nil)
 		w := httptest.NewRecorder()
 		handler.DeleteAllPosts(w, req)
 
+		if w.Code != http.StatusPreconditionRequired {
+			t.Fatalf("Expected status code %d, but got %d", http.StatusPreconditionRequired, w.Code)
+		}
+
+		if remaining, _ := store.GetAll(t.Context()); len(remaining) != len(posts) {
+			t.Errorf("posts were deleted without confirmation")
+		}
+	})
+
+	t.Run("Delete All Posts", func(t *testing.T) {
+		// Request
+		req := httptest.NewRequest(http.MethodDelete, "/posts?confirm=true", nil)
+		w := httptest.NewRecorder()
+		handler.DeleteAllPosts(w, req)
+
 		if w.Code != http.StatusNoContent {
 			t.Fatalf("Expected status code %d, but got %d", http.StatusNoContent, w.Code)
 		}
 
 		// test to see if content was actually deleted by called store method directly
-		_, err := store.GetAll()
+		_, err := store.GetAll(t.Context())
 		if err == nil {
 			t.Errorf("expected error for emtpy content but got nil")
 		}
 
 	})
 }
+
+func TestPostHandler_UpdatePost(t *testing.T) {
+	// setup
+	byAlice := service.WithAuthor("alice")
+	store := store.NewInMemoryStore()
+	service := service.NewPostService(store)
+	handler := NewPostHandler(service)
+
+	post, err := service.CreatePost(t.Context(), "Original title", "Original content", byAlice)
+	if err != nil {
+		t.Fatalf("Error creating posts")
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		id          string
+		body        string
+		wantStatus  int
+		wantName    string
+		wantContent string
+	}{
+		{
+			name:        "PUT replaces the post",
+			method:      http.MethodPut,
+			id:          post.ID,
+			body:        `{"name": "Put title", "content": "Put content"}`,
+			wantStatus:  http.StatusOK,
+			wantName:    "Put title",
+			wantContent: "Put content",
+		},
+		{
+			name:       "PUT needs every field",
+			method:     http.MethodPut,
+			id:         post.ID,
+			body:       `{"name": "Only a title"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "PATCH changes only what is sent",
+			method:      http.MethodPatch,
+			id:          post.ID,
+			body:        `{"content": "Patched content"}`,
+			wantStatus:  http.StatusOK,
+			wantName:    "Put title",
+			wantContent: "Patched content",
+		},
+		{
+			name:       "PATCH with nothing to change",
+			method:     http.MethodPatch,
+			id:         post.ID,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "business rules still apply",
+			method:     http.MethodPatch,
+			id:         post.ID,
+			body:       `{"content": "hi"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "bad json data",
+			method:     http.MethodPatch,
+			id:         post.ID,
+			body:       `{"bad":}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown post",
+			method:     http.MethodPatch,
+			id:         "invalidID",
+			body:       `{"content": "Does not matter"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := withPrincipal(httptest.NewRequest(tc.method, "/posts/"+tc.id, strings.NewReader(tc.body)), "alice")
+			req.SetPathValue("id", tc.id)
+			w := httptest.NewRecorder()
+			handler.UpdatePost(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var resp CreatePostResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("unmarshal: %v", err)
+				}
+				if resp.Name != tc.wantName || resp.Content != tc.wantContent {
+					t.Errorf("expected %q/%q, got %q/%q", tc.wantName, tc.wantContent, resp.Name, resp.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestPostHandler_DeletePost(t *testing.T) {
+	// setup
+	byAlice := service.WithAuthor("alice")
+	store := store.NewInMemoryStore()
+	service := service.NewPostService(store)
+	handler := NewPostHandler(service)
+
+	post, err := service.CreatePost(t.Context(), "Doomed post", "About to be deleted", byAlice)
+	if err != nil {
+		t.Fatalf("Error creating posts")
+	}
+	keep, err := service.CreatePost(t.Context(), "Kept post", "Should survive", byAlice)
+	if err != nil {
+		t.Fatalf("Error creating posts")
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{name: "delete existing post", id: post.ID, wantStatus: http.StatusNoContent},
+		{name: "delete it again", id: post.ID, wantStatus: http.StatusNotFound},
+		{name: "delete unknown post", id: "invalidID", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := withPrincipal(httptest.NewRequest(http.MethodDelete, "/posts/"+tc.id, nil), "alice")
+			req.SetPathValue("id", tc.id)
+			w := httptest.NewRecorder()
+			handler.DeletePost(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+		})
+	}
+
+	if _, err := store.GetByID(t.Context(), keep.ID); err != nil {
+		t.Errorf("deleting one post removed another: %v", err)
+	}
+}
+
+func TestPostHandler_EditAndDeleteNeedTheAuthor(t *testing.T) {
+	// setup
+	postService := service.NewPostService(store.NewInMemoryStore())
+	handler := NewPostHandler(postService)
+
+	admin := func(req *http.Request) *http.Request {
+		return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{ID: "root", Roles: []string{auth.RoleAdmin}}))
+	}
+	bob := func(req *http.Request) *http.Request { return withPrincipal(req, "bob") }
+
+	tests := []struct {
+		name       string
+		method     string
+		draft      bool
+		as         func(*http.Request) *http.Request
+		wantStatus int
+	}{
+		// a draft someone else can't see is not found rather than forbidden, so its id doesn't leak
+		{name: "someone else can't edit a draft", method: http.MethodPatch, draft: true, as: bob, wantStatus: http.StatusNotFound},
+		{name: "someone else can't delete a draft", method: http.MethodDelete, draft: true, as: bob, wantStatus: http.StatusNotFound},
+		{name: "someone else can't edit a public post", method: http.MethodPut, as: bob, wantStatus: http.StatusForbidden},
+		{name: "someone else can't delete a public post", method: http.MethodDelete, as: bob, wantStatus: http.StatusForbidden},
+		{name: "an admin can edit a draft", method: http.MethodPatch, draft: true, as: admin, wantStatus: http.StatusOK},
+		{name: "an admin can delete a public post", method: http.MethodDelete, as: admin, wantStatus: http.StatusNoContent},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []service.PostOption{service.WithAuthor("alice")}
+			if tc.draft {
+				opts = append(opts, service.WithStatus(models.StatusDraft))
+			}
+			post, err := postService.CreatePost(t.Context(), "Alice's post "+strconv.Itoa(i), "Written by alice", opts...)
+			if err != nil {
+				t.Fatalf("CreatePost: %v", err)
+			}
+
+			body := `{"name": "Taken over", "content": "Someone else's words"}`
+			req := tc.as(httptest.NewRequest(tc.method, "/posts/"+post.ID, strings.NewReader(body)))
+			req.SetPathValue("id", post.ID)
+			w := httptest.NewRecorder()
+			if tc.method == http.MethodDelete {
+				handler.DeletePost(w, req)
+			} else {
+				handler.UpdatePost(w, req)
+			}
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d: %s", tc.wantStatus, w.Code, w.Body)
+			}
+			if tc.wantStatus == http.StatusNotFound || tc.wantStatus == http.StatusForbidden {
+				got, err := postService.GetPostByID(t.Context(), post.ID)
+				if err != nil || got.Name != post.Name {
+					t.Errorf("expected the post to be untouched, got %+v, %v", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPostHandler_GetAll_Pagination(t *testing.T) {
+	// setup
+	store := store.NewInMemoryStore()
+	service := service.NewPostService(store)
+	handler := NewPostHandler(service)
+
+	for i := range 5 {
+		if _, err := service.CreatePost(t.Context(), "Post "+strconv.Itoa(i), "Some Content"+strconv.Itoa(i)); err != nil {
+			t.Fatalf("Error creating posts")
+		}
+	}
+
+	t.Run("follow next links to the end", func(t *testing.T) {
+		target := "/posts?limit=2&sort=name"
+		names := []string{}
+
+		for pages := 0; target != ""; pages++ {
+			if pages > 5 {
+				t.Fatalf("too many pages, next links never stop")
+			}
+
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+			handler.GetPostsAll(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, but got %d", http.StatusOK, w.Code)
+			}
+
+			var page []CreatePostResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("Error unmarshaling response: %v", err)
+			}
+			for _, p := range page {
+				names = append(names, p.Name)
+			}
+
+			target = ""
+			if link := w.Header().Get("Link"); link != "" {
+				target = strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+			}
+		}
+
+		want := "Post 0,Post 1,Post 2,Post 3,Post 4"
+		if got := strings.Join(names, ","); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("created_before in the past matches nothing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts?created_before=2000-01-01T00:00:00Z", nil)
+		w := httptest.NewRecorder()
+		handler.GetPostsAll(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, but got %d", http.StatusOK, w.Code)
+		}
+		if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+			t.Errorf("Expected empty list, got %s", body)
+		}
+	})
+
+	badQueries := []string{
+		"/posts?limit=abc",
+		"/posts?limit=1000",
+		"/posts?offset=-1",
+		"/posts?sort=id",
+		"/posts?order=sideways",
+		"/posts?created_after=yesterday",
+	}
+	for _, target := range badQueries {
+		t.Run("reject "+target, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+			handler.GetPostsAll(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, but got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestPostHandler_SearchPosts(t *testing.T) {
+	// setup
+	store := store.NewInMemoryStore()
+	postService := service.NewPostService(store)
+	handler := NewPostHandler(postService)
+
+	if _, err := postService.CreatePost(t.Context(), "Concurrency in Go", "Goroutines and channels"); err != nil {
+		t.Fatalf("Error creating posts")
+	}
+	if _, err := postService.CreatePost(t.Context(), "Baking bread", "Flour, water, salt and time"); err != nil {
+		t.Fatalf("Error creating posts")
+	}
+	// drafts that match better than the public post, they must not use up the limit
+	for _, title := range []string{"Bread notes", "More bread notes", "Bread ideas"} {
+		_, err := postService.CreatePost(t.Context(), title, "bread bread bread", service.WithAuthor("alice"), service.WithStatus(models.StatusDraft))
+		if err != nil {
+			t.Fatalf("Error creating drafts")
+		}
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantNames  []string
+	}{
+		{name: "match", target: "/posts/search?q=channels", wantStatus: http.StatusOK, wantNames: []string{"Concurrency in Go"}},
+		{name: "hidden drafts don't fill the page", target: "/posts/search?q=bread&limit=1", wantStatus: http.StatusOK, wantNames: []string{"Baking bread"}},
+		{name: "no match", target: "/posts/search?q=python", wantStatus: http.StatusOK, wantNames: []string{}},
+		{name: "missing q", target: "/posts/search", wantStatus: http.StatusBadRequest},
+		{name: "bad limit", target: "/posts/search?q=go&limit=x", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			w := httptest.NewRecorder()
+			handler.SearchPosts(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp []SearchResultResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			names := []string{}
+			for _, r := range resp {
+				names = append(names, r.Name)
+				if r.Score <= 0 {
+					t.Errorf("expected a positive score, got %v", r.Score)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tc.wantNames, ",") {
+				t.Errorf("expected %v got %v", tc.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestPostHandler_GetPostBySlug(t *testing.T) {
+	// setup
+	store := store.NewInMemoryStore()
+	service := service.NewPostService(store)
+	handler := NewPostHandler(service)
+
+	post, err := service.CreatePost(t.Context(), "Hello World", "Some content here")
+	if err != nil {
+		t.Fatalf("Error creating posts")
+	}
+	title := "Hello Gophers"
+	if _, err := service.UpdatePost(t.Context(), post.ID, "", &title, nil); err != nil {
+		t.Fatalf("Error renaming post")
+	}
+
+	tests := []struct {
+		name         string
+		slug         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "current slug", slug: "hello-gophers", wantStatus: http.StatusOK},
+		{name: "old slug redirects", slug: "hello-world", wantStatus: http.StatusMovedPermanently, wantLocation: "/post/by-slug/hello-gophers"},
+		{name: "unknown slug", slug: "nope", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/post/by-slug/"+tc.slug, nil)
+			req.SetPathValue("slug", tc.slug)
+			w := httptest.NewRecorder()
+			handler.GetPostBySlug(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Location"); got != tc.wantLocation {
+				t.Errorf("expected Location %q got %q", tc.wantLocation, got)
+			}
+			if tc.wantStatus == http.StatusOK {
+				var resp CreatePostResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				if resp.ID != post.ID || resp.Slug != "hello-gophers" {
+					t.Errorf("unexpected post %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestPostHandler_Lifecycle(t *testing.T) {
+	// setup
+	service := service.NewPostService(store.NewInMemoryStore())
+	handler := NewPostHandler(service)
+
+	admin := func(req *http.Request) *http.Request {
+		return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{ID: "root", Roles: []string{auth.RoleAdmin}}))
+	}
+
+	req := withPrincipal(httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{"name": "Draft", "content": "Not out yet", "status": "draft"}`)), "alice")
+	w := httptest.NewRecorder()
+	handler.CreatePost(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d but got %d: %s", http.StatusCreated, w.Code, w.Body)
+	}
+	var draft CreatePostResponse
+	json.Unmarshal(w.Body.Bytes(), &draft)
+	if draft.Status != "draft" || draft.PublishAt != "" {
+		t.Fatalf("unexpected draft %+v", draft)
+	}
+	service.CreatePost(t.Context(), "Public", "Out for everyone")
+
+	t.Run("drafts are only listed for their author and admins", func(t *testing.T) {
+		viewers := []struct {
+			name string
+			req  *http.Request
+			want int
+		}{
+			{name: "anonymous", req: httptest.NewRequest(http.MethodGet, "/posts", nil), want: 1},
+			{name: "someone else", req: withPrincipal(httptest.NewRequest(http.MethodGet, "/posts", nil), "bob"), want: 1},
+			{name: "author", req: withPrincipal(httptest.NewRequest(http.MethodGet, "/posts", nil), "alice"), want: 2},
+			{name: "admin", req: admin(httptest.NewRequest(http.MethodGet, "/posts", nil)), want: 2},
+			{name: "author's drafts only", req: withPrincipal(httptest.NewRequest(http.MethodGet, "/posts?status=draft", nil), "alice"), want: 1},
+		}
+		for _, v := range viewers {
+			w := httptest.NewRecorder()
+			handler.GetPostsAll(w, v.req)
+			var resp []CreatePostResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp) != v.want {
+				t.Errorf("%s: expected %d posts, got %d", v.name, v.want, len(resp))
+			}
+		}
+	})
+
+	t.Run("drafts are not found by anyone else", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.GetPostByID(w, httptest.NewRequest(http.MethodGet, "/post/"+draft.ID, nil))
+		decodeProblem(t, w, http.StatusNotFound)
+
+		w = httptest.NewRecorder()
+		handler.GetPostByID(w, withPrincipal(httptest.NewRequest(http.MethodGet, "/post/"+draft.ID, nil), "alice"))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected the author to see the draft, got %d", w.Code)
+		}
+	})
+
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	tests := []struct {
+		name       string
+		serve      http.HandlerFunc
+		principal  string
+		body       string
+		wantStatus int
+		wantState  string
+	}{
+		{name: "someone else can't publish", serve: handler.PublishPost, principal: "bob", wantStatus: http.StatusNotFound},
+		{name: "schedule", serve: handler.PublishPost, principal: "alice", body: `{"publish_at": "` + future.Format(time.RFC3339) + `"}`, wantStatus: http.StatusOK, wantState: "scheduled"},
+		{name: "publish now", serve: handler.PublishPost, principal: "alice", wantStatus: http.StatusOK, wantState: "published"},
+		{name: "someone else can't unpublish", serve: handler.UnpublishPost, principal: "bob", wantStatus: http.StatusForbidden},
+		{name: "unpublish", serve: handler.UnpublishPost, principal: "alice", wantStatus: http.StatusOK, wantState: "draft"},
+		{name: "archive", serve: handler.ArchivePost, principal: "alice", wantStatus: http.StatusOK, wantState: "archived"},
+		{name: "bad json", serve: handler.PublishPost, principal: "alice", body: `{"publish_at":`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := withPrincipal(httptest.NewRequest(http.MethodPost, "/posts/"+draft.ID+"/publish", strings.NewReader(tc.body)), tc.principal)
+			req.SetPathValue("id", draft.ID)
+			w := httptest.NewRecorder()
+			tc.serve(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status code %d but got %d: %s", tc.wantStatus, w.Code, w.Body)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var resp CreatePostResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Status != tc.wantState {
+				t.Errorf("expected status %s got %s", tc.wantState, resp.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/diff"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type RevisionResponse struct {
+	Number    int    `json:"number"`
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	EditorID  string `json:"editor_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+func newRevisionResponse(rev *models.Revision) RevisionResponse {
+	return RevisionResponse{
+		Number:    rev.Number,
+		Name:      rev.Name,
+		Content:   rev.Content,
+		EditorID:  rev.EditorID,
+		CreatedAt: rev.CreatedAt.Format(timeFormat),
+	}
+}
+
+// DiffLineResponse is one line of a diff, op is " ", "+" or "-" like in a unified diff
+type DiffLineResponse struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiffResponse struct {
+	From    RevisionResponse   `json:"from"`
+	To      RevisionResponse   `json:"to"`
+	Title   []DiffLineResponse `json:"title"`
+	Content []DiffLineResponse `json:"content"`
+}
+
+func newDiffLinesResponse(lines []diff.Line) []DiffLineResponse {
+	response := []DiffLineResponse{}
+	for _, line := range lines {
+		response = append(response, DiffLineResponse{Op: string(line.Op), Text: line.Text})
+	}
+	return response
+}
+
+// ListRevisions handles GET /posts/{id}/revisions, oldest first.
+// The history of a post nobody else may read is just as hidden as the post.
+func (h *PostHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.checkVisible(r, id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	revisions, err := h.Service.ListRevisions(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []RevisionResponse{}
+	for _, rev := range revisions {
+		response = append(response, newRevisionResponse(rev))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DiffRevisions handles GET /posts/{id}/revisions/diff?from=1&to=2
+func (h *PostHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	from, err := revisionNumber(r.URL.Query(), "from")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	to, err := revisionNumber(r.URL.Query(), "to")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.checkVisible(r, id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	d, err := h.Service.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RevisionDiffResponse{
+		From:    newRevisionResponse(d.From),
+		To:      newRevisionResponse(d.To),
+		Title:   newDiffLinesResponse(d.Title),
+		Content: newDiffLinesResponse(d.Content),
+	})
+}
+
+// RestoreRevision handles POST /posts/{id}/revisions/{rev}/restore, the restore itself becomes the newest revision
+func (h *PostHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: revision %q", ErrInvalidParameter, r.PathValue("rev")))
+		return
+	}
+
+	var editorID string
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		editorID = principal.ID
+	}
+
+	h.managePost(w, r, func(ctx context.Context, id string) (*models.Post, error) {
+		return h.Service.RestoreRevision(ctx, id, number, editorID)
+	})
+}
+
+// checkVisible returns store.ErrNotFound for posts that don't exist and for posts the caller may not see
+func (h *PostHandler) checkVisible(r *http.Request, id string) error {
+	post, err := h.Service.GetPostByID(r.Context(), id)
+	return visibleOrNotFound(r, post, err)
+}
+
+// visibleOrNotFound passes err through and turns a post the caller may not see into store.ErrNotFound,
+// so a hidden post looks exactly like one that doesn't exist
+func visibleOrNotFound(r *http.Request, post *models.Post, err error) error {
+	if err != nil {
+		return err
+	}
+	if !canView(r, post) {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// revisionNumber reads a required revision number from the query string
+func revisionNumber(values url.Values, key string) (int, error) {
+	v := values.Get(key)
+	number, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s %q, expected a revision number", ErrInvalidParameter, key, v)
+	}
+	return number, nil
+}
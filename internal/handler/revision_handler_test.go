@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestRevisionHandler(t *testing.T) {
+	// setup
+	postService := service.NewPostService(store.NewInMemoryStore())
+	handler := NewPostHandler(postService)
+
+	post, err := postService.CreatePost(t.Context(), "Hello", "one\ntwo", service.WithAuthor("alice"))
+	if err != nil {
+		t.Fatalf("Error creating post")
+	}
+	draft, err := postService.CreatePost(t.Context(), "Secret", "Not out yet", service.WithAuthor("alice"), service.WithStatus(models.StatusDraft))
+	if err != nil {
+		t.Fatalf("Error creating draft")
+	}
+
+	// an admin edits alice's post, the revision records who made the change
+	req := httptest.NewRequest(http.MethodPatch, "/posts/"+post.ID, strings.NewReader(`{"content": "one\n2"}`))
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{ID: "root", Roles: []string{auth.RoleAdmin}}))
+	req.SetPathValue("id", post.ID)
+	w := httptest.NewRecorder()
+	handler.UpdatePost(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d but got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	list := func(req *http.Request, id string) *httptest.ResponseRecorder {
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.ListRevisions(w, req)
+		return w
+	}
+
+	t.Run("list revisions", func(t *testing.T) {
+		w := list(httptest.NewRequest(http.MethodGet, "/posts/"+post.ID+"/revisions", nil), post.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+		}
+		var resp []RevisionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 2 || resp[0].EditorID != "alice" || resp[1].EditorID != "root" || resp[1].Content != "one\n2" {
+			t.Errorf("unexpected revisions %+v", resp)
+		}
+	})
+
+	t.Run("a draft's history is hidden like the draft", func(t *testing.T) {
+		w := list(httptest.NewRequest(http.MethodGet, "/posts/"+draft.ID+"/revisions", nil), draft.ID)
+		decodeProblem(t, w, http.StatusNotFound)
+
+		w = list(withPrincipal(httptest.NewRequest(http.MethodGet, "/posts/"+draft.ID+"/revisions", nil), "alice"), draft.ID)
+		if w.Code != http.StatusOK {
+			t.Errorf("expected the author to see the history, got %d", w.Code)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			query      string
+			wantStatus int
+		}{
+			{name: "between revisions", query: "?from=1&to=2", wantStatus: http.StatusOK},
+			{name: "missing from", query: "?to=2", wantStatus: http.StatusBadRequest},
+			{name: "unknown revision", query: "?from=1&to=7", wantStatus: http.StatusNotFound},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/posts/"+post.ID+"/revisions/diff"+tc.query, nil)
+				req.SetPathValue("id", post.ID)
+				w := httptest.NewRecorder()
+				handler.DiffRevisions(w, req)
+
+				if tc.wantStatus != http.StatusOK {
+					decodeProblem(t, w, tc.wantStatus)
+					return
+				}
+				if w.Code != tc.wantStatus {
+					t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+				}
+				var resp RevisionDiffResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				want := []DiffLineResponse{{" ", "one"}, {"-", "two"}, {"+", "2"}}
+				if resp.From.Number != 1 || resp.To.Number != 2 || len(resp.Content) != len(want) {
+					t.Fatalf("unexpected diff %+v", resp)
+				}
+				for i := range want {
+					if resp.Content[i] != want[i] {
+						t.Errorf("line %d: got %+v want %+v", i, resp.Content[i], want[i])
+					}
+				}
+				if len(resp.Title) != 1 || resp.Title[0].Op != " " {
+					t.Errorf("expected an unchanged title, got %+v", resp.Title)
+				}
+			})
+		}
+	})
+
+	t.Run("revisions too different to diff", func(t *testing.T) {
+		numbered := func(prefix string) string {
+			lines := make([]string, 3000)
+			for i := range lines {
+				lines[i] = prefix + strconv.Itoa(i)
+			}
+			return strings.Join(lines, "\n")
+		}
+		big, err := postService.CreatePost(t.Context(), "Big", numbered("old"), service.WithAuthor("alice"))
+		if err != nil {
+			t.Fatalf("Error creating post")
+		}
+		rewritten := numbered("new")
+		if _, err := postService.UpdatePost(t.Context(), big.ID, "alice", nil, &rewritten); err != nil {
+			t.Fatalf("Error updating post: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/posts/"+big.ID+"/revisions/diff?from=1&to=2", nil)
+		req.SetPathValue("id", big.ID)
+		w := httptest.NewRecorder()
+		handler.DiffRevisions(w, req)
+		if problem := decodeProblem(t, w, http.StatusUnprocessableEntity); problem.Code != "diff_too_large" {
+			t.Errorf("expected diff_too_large, got %q", problem.Code)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			user       string
+			rev        string
+			wantStatus int
+		}{
+			{name: "needs the author", user: "bob", rev: "1", wantStatus: http.StatusForbidden},
+			{name: "bad revision", user: "alice", rev: "first", wantStatus: http.StatusBadRequest},
+			{name: "unknown revision", user: "alice", rev: "9", wantStatus: http.StatusNotFound},
+			{name: "author restores", user: "alice", rev: "1", wantStatus: http.StatusOK},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				req := withPrincipal(httptest.NewRequest(http.MethodPost, "/posts/"+post.ID+"/revisions/"+tc.rev+"/restore", nil), tc.user)
+				req.SetPathValue("id", post.ID)
+				req.SetPathValue("rev", tc.rev)
+				w := httptest.NewRecorder()
+				handler.RestoreRevision(w, req)
+
+				if tc.wantStatus != http.StatusOK {
+					decodeProblem(t, w, tc.wantStatus)
+					return
+				}
+				if w.Code != tc.wantStatus {
+					t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+				}
+				var resp CreatePostResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				if resp.Content != "one\ntwo" {
+					t.Errorf("expected the first content back, got %q", resp.Content)
+				}
+				if revisions, _ := postService.ListRevisions(t.Context(), post.ID); len(revisions) != 3 || revisions[2].EditorID != "alice" {
+					t.Errorf("expected the restore to be recorded as revision 3")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+)
+
+type TagResponse struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// ListTags handles GET /tags, every tag in use with its post count, most used first
+func (h *PostHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.Service.ListTags(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []TagResponse{}
+	for _, tag := range tags {
+		response = append(response, TagResponse{Tag: tag.Tag, Count: tag.Count})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTagPosts handles GET /tags/{tag}/posts, it takes the same query params as GET /posts.
+// An unknown tag is just an empty list, not a 404.
+func (h *PostHandler) GetTagPosts(w http.ResponseWriter, r *http.Request) {
+	query, err := parsePostQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query.Tag = service.NormalizeFilter(r.PathValue("tag"))
+	applyVisibility(r, &query)
+
+	page, err := h.Service.ListPosts(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := []CreatePostResponse{}
+	for _, post := range page.Posts {
+		response = append(response, newPostResponse(post))
+	}
+
+	setNextLink(w, r, query.Offset, page)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
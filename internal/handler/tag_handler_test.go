@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+)
+
+func TestTagHandler(t *testing.T) {
+	// setup
+	postHandler := NewPostHandler(service.NewPostService(store.NewInMemoryStore()))
+
+	create := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		postHandler.CreatePost(w, req)
+		return w
+	}
+
+	t.Run("create normalizes tags and category", func(t *testing.T) {
+		w := create(`{"name": "First", "content": "First content", "tags": ["Go", "SQL", "go"], "category": "Tutorials"}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status code %d but got %d: %s", http.StatusCreated, w.Code, w.Body)
+		}
+		var resp CreatePostResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if strings.Join(resp.Tags, ",") != "go,sql" || resp.Category != "tutorials" {
+			t.Errorf("unexpected tags %v and category %q", resp.Tags, resp.Category)
+		}
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		w := create(`{"name": "Bad", "content": "Bad content", "tags": ["c++"]}`)
+		if problem := decodeProblem(t, w, http.StatusUnprocessableEntity); problem.Code != "invalid_tag" {
+			t.Errorf("unexpected problem %+v", problem)
+		}
+	})
+
+	create(`{"name": "Second", "content": "Second content", "tags": ["go"]}`)
+	create(`{"name": "Untagged", "content": "No tags at all"}`)
+
+	t.Run("list tags with counts", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tags", nil)
+		w := httptest.NewRecorder()
+		postHandler.ListTags(w, req)
+
+		var resp []TagResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		want := []TagResponse{{Tag: "go", Count: 2}, {Tag: "sql", Count: 1}}
+		if len(resp) != len(want) || resp[0] != want[0] || resp[1] != want[1] {
+			t.Errorf("got %+v want %+v", resp, want)
+		}
+	})
+
+	tagTests := []struct {
+		name      string
+		tag       string
+		query     string
+		wantCount int
+		wantNext  bool
+	}{
+		{name: "posts by tag", tag: "go", wantCount: 2},
+		{name: "tag lookup is case insensitive", tag: "SQL", wantCount: 1},
+		{name: "unknown tag is empty", tag: "rust", wantCount: 0},
+		{name: "paginated", tag: "go", query: "?limit=1", wantCount: 1, wantNext: true},
+	}
+	for _, tc := range tagTests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tags/"+tc.tag+"/posts"+tc.query, nil)
+			req.SetPathValue("tag", tc.tag)
+			w := httptest.NewRecorder()
+			postHandler.GetTagPosts(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+			}
+			var resp []CreatePostResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp) != tc.wantCount {
+				t.Errorf("expected %d posts but got %d", tc.wantCount, len(resp))
+			}
+			if hasNext := w.Header().Get("Link") != ""; hasNext != tc.wantNext {
+				t.Errorf("expected next link %v, got Link %q", tc.wantNext, w.Header().Get("Link"))
+			}
+		})
+	}
+
+	t.Run("tag filter on GET /posts", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts?tag=sql&category=tutorials", nil)
+		w := httptest.NewRecorder()
+		postHandler.GetPostsAll(w, req)
+
+		var resp []CreatePostResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 1 || resp[0].Name != "First" {
+			t.Errorf("unexpected posts %+v", resp)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// ListTrash handles GET /trash, most recently deleted first, paginated like GET /posts.
+// Admins see every trashed post, everyone else only the ones they wrote.
+func (h *PostHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	var query models.TrashQuery
+	var err error
+	if query.Limit, query.Offset, err = parsePage(r.URL.Query()); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeError(w, r, auth.ErrNoCredentials)
+		return
+	}
+	if !principal.HasRole(auth.RoleAdmin) {
+		query.AuthorID = principal.ID
+	}
+
+	page, err := h.Service.ListTrash(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := make([]CreatePostResponse, 0, len(page.Posts))
+	for _, post := range page.Posts {
+		response = append(response, newPostResponse(post))
+	}
+	setNextLink(w, r, query.Offset, page)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RestorePost handles POST /trash/{id}/restore, the post comes back with its comments and revisions.
+// Someone else's trash is as invisible as it is in GET /trash, so that is a 404 rather than a 403.
+func (h *PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	post, err := h.Service.GetTrashedPost(r.Context(), r.PathValue("id"))
+	if err == nil && !canManage(r, post) {
+		err = store.ErrNotFound
+	}
+	if err == nil {
+		post, err = h.Service.RestorePost(r.Context(), post.ID)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPostResponse(post))
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/auth"
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestTrashHandler(t *testing.T) {
+	// setup
+	postService := service.NewPostService(store.NewInMemoryStore())
+	handler := NewPostHandler(postService)
+
+	alices, _ := postService.CreatePost(t.Context(), "Alice's post", "Written by alice", service.WithAuthor("alice"))
+	bobs, _ := postService.CreatePost(t.Context(), "Bob's post", "Written by bob", service.WithAuthor("bob"))
+	for _, post := range []*models.Post{alices, bobs} {
+		req := withPrincipal(httptest.NewRequest(http.MethodDelete, "/posts/"+post.ID, nil), post.AuthorID)
+		req.SetPathValue("id", post.ID)
+		w := httptest.NewRecorder()
+		handler.DeletePost(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected status code %d but got %d", http.StatusNoContent, w.Code)
+		}
+	}
+
+	t.Run("list trash", func(t *testing.T) {
+		admin := httptest.NewRequest(http.MethodGet, "/trash", nil)
+		admin = admin.WithContext(auth.WithPrincipal(admin.Context(), &auth.Principal{ID: "root", Roles: []string{auth.RoleAdmin}}))
+
+		viewers := []struct {
+			name string
+			req  *http.Request
+			want int
+		}{
+			{name: "author sees their own", req: withPrincipal(httptest.NewRequest(http.MethodGet, "/trash", nil), "alice"), want: 1},
+			{name: "someone else", req: withPrincipal(httptest.NewRequest(http.MethodGet, "/trash", nil), "carol"), want: 0},
+			{name: "admin sees everything", req: admin, want: 2},
+		}
+
+		for _, tc := range viewers {
+			t.Run(tc.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				handler.ListTrash(w, tc.req)
+				if w.Code != http.StatusOK {
+					t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+				}
+				var resp []CreatePostResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				if len(resp) != tc.want {
+					t.Fatalf("expected %d trashed posts, got %d", tc.want, len(resp))
+				}
+				for _, post := range resp {
+					if post.DeletedAt == "" {
+						t.Errorf("expected deleted_at on %s", post.ID)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("list trash pages", func(t *testing.T) {
+		admin := &auth.Principal{ID: "root", Roles: []string{auth.RoleAdmin}}
+		req := httptest.NewRequest(http.MethodGet, "/trash?limit=1", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), admin))
+		w := httptest.NewRecorder()
+		handler.ListTrash(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+		}
+		var resp []CreatePostResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 1 {
+			t.Fatalf("expected 1 trashed post, got %d", len(resp))
+		}
+		if want := `</trash?limit=1&offset=1>; rel="next"`; w.Header().Get("Link") != want {
+			t.Errorf("expected Link %q, got %q", want, w.Header().Get("Link"))
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/trash?limit=1&offset=1", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), admin))
+		w = httptest.NewRecorder()
+		handler.ListTrash(w, req)
+		if link := w.Header().Get("Link"); link != "" {
+			t.Errorf("expected no Link on the last page, got %q", link)
+		}
+
+		for _, query := range []string{"limit=abc", "limit=1000", "offset=-1"} {
+			req := withPrincipal(httptest.NewRequest(http.MethodGet, "/trash?"+query, nil), "alice")
+			w := httptest.NewRecorder()
+			handler.ListTrash(w, req)
+			decodeProblem(t, w, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			user       string
+			id         string
+			wantStatus int
+		}{
+			{name: "someone else's post", user: "alice", id: bobs.ID, wantStatus: http.StatusNotFound},
+			{name: "not in the trash", user: "alice", id: "nope", wantStatus: http.StatusNotFound},
+			{name: "author restores", user: "alice", id: alices.ID, wantStatus: http.StatusOK},
+			{name: "already restored", user: "alice", id: alices.ID, wantStatus: http.StatusNotFound},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				req := withPrincipal(httptest.NewRequest(http.MethodPost, "/trash/"+tc.id+"/restore", nil), tc.user)
+				req.SetPathValue("id", tc.id)
+				w := httptest.NewRecorder()
+				handler.RestorePost(w, req)
+
+				if tc.wantStatus != http.StatusOK {
+					decodeProblem(t, w, tc.wantStatus)
+					return
+				}
+				if w.Code != tc.wantStatus {
+					t.Fatalf("expected status code %d but got %d", tc.wantStatus, w.Code)
+				}
+				var resp CreatePostResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				if resp.ID != alices.ID || resp.DeletedAt != "" {
+					t.Errorf("unexpected restored post %+v", resp)
+				}
+				if _, err := postService.GetPostByID(t.Context(), alices.ID); err != nil {
+					t.Errorf("expected the post to be back, got %v", err)
+				}
+			})
+		}
+	})
+}
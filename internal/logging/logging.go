@@ -0,0 +1,84 @@
+// Package logging sets up log/slog and carries a request ID through the context, so every line
+// logged while serving a request, from the handler down to the stores, can be tied back to it.
+//
+// Anything that has the request context logs with the *Context variants and gets the ID for free:
+//
+//	slog.InfoContext(ctx, "post created", "post_id", post.ID)
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// RequestIDHeader is read from incoming requests and set on every response
+const RequestIDHeader = "X-Request-ID"
+
+// Formats are the log line formats New supports, config validates -log-format against them
+var Formats = []string{"text", "json"}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID WithRequestID put into ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 128 bit ID, hex encoded
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// New builds a logger writing format lines, "text" or "json", at level and up to w.
+// Records logged with a context get its request ID as request_id.
+func New(w io.Writer, format string, level slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch format {
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+	return slog.New(NewContextHandler(h)), nil
+}
+
+// ContextHandler adds the request ID from the context to every record before passing it on
+// Design pattern: Decorator - same idea as the HTTP middlewares, around a slog.Handler
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewContextHandler(h.Handler.WithAttrs(attrs))
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return NewContextHandler(h.Handler.WithGroup(name))
+}
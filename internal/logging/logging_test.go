@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(t.Context()); id != "" {
+		t.Errorf("Expected no ID on a fresh context, got %q", id)
+	}
+
+	ctx := WithRequestID(t.Context(), "abc-123")
+	if id := RequestID(ctx); id != "abc-123" {
+		t.Errorf("Got %q want abc-123", id)
+	}
+
+	a, b := NewRequestID(), NewRequestID()
+	if len(a) != 32 || a == b {
+		t.Errorf("Expected two distinct 32 char IDs, got %q and %q", a, b)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		format string
+		ctx    context.Context
+		want   []string
+	}{
+		{format: "text", ctx: WithRequestID(context.Background(), "r1"), want: []string{"msg=hello", "post_id=p1", "request_id=r1"}},
+		{format: "json", ctx: WithRequestID(context.Background(), "r2"), want: []string{`"msg":"hello"`, `"post_id":"p1"`, `"request_id":"r2"`}},
+		{format: "json", ctx: context.Background(), want: []string{`"msg":"hello"`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tc.format, slog.LevelInfo)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			logger.DebugContext(tc.ctx, "below the level")
+			logger.With("post_id", "p1").InfoContext(tc.ctx, "hello")
+
+			out := buf.String()
+			if strings.Contains(out, "below the level") {
+				t.Errorf("Debug line should have been dropped: %s", out)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("Expected %s in %s", want, out)
+				}
+			}
+			if RequestID(tc.ctx) == "" && strings.Contains(out, "request_id") {
+				t.Errorf("Expected no request_id without one in the context: %s", out)
+			}
+			if tc.format == "json" && !json.Valid(buf.Bytes()) {
+				t.Errorf("Expected valid JSON: %s", out)
+			}
+		})
+	}
+
+	if _, err := New(&bytes.Buffer{}, "xml", slog.LevelInfo); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
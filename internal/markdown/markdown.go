@@ -0,0 +1,118 @@
+// Package markdown renders post content written in CommonMark with the GitHub extensions
+// (tables, strikethrough, autolinks, task lists) to HTML that is safe to put in a page.
+//
+// Raw HTML in the source is passed through goldmark and then everything goes through an
+// allowlist sanitizer, so scripts, event handler attributes and javascript: links never
+// reach the reader. Rendering is cached by content, the same text is only rendered once.
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"regexp"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// DefaultCacheSize is how many rendered documents NewRenderer keeps around
+const DefaultCacheSize = 1024
+
+// Renderer turns markdown into sanitized HTML.
+// It is safe for concurrent use.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu    sync.Mutex
+	size  int
+	order *list.List // front is the most recently used
+	cache map[[sha256.Size]byte]*list.Element
+}
+
+type cacheEntry struct {
+	key  [sha256.Size]byte
+	html string
+}
+
+// NewRenderer returns a renderer caching up to cacheSize documents, 0 or less disables the cache
+func NewRenderer(cacheSize int) *Renderer {
+	policy := bluemonday.UGCPolicy()
+	// GFM task lists render as disabled checkboxes
+	policy.AllowAttrs("type").Matching(bluemonday.SpaceSeparatedTokens).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	// fenced code keeps its language so the frontend can highlight it
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+
+	return &Renderer{
+		md: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			// the sanitizer decides what raw HTML survives, not goldmark
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+		policy: policy,
+		size:   cacheSize,
+		order:  list.New(),
+		cache:  map[[sha256.Size]byte]*list.Element{},
+	}
+}
+
+// Render returns the sanitized HTML for src
+func (r *Renderer) Render(src string) (string, error) {
+	key := sha256.Sum256([]byte(src))
+	if html, ok := r.cached(key); ok {
+		return html, nil
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	html := r.policy.SanitizeReader(&buf).String()
+
+	r.remember(key, html)
+	return html, nil
+}
+
+func (r *Renderer) cached(key [sha256.Size]byte) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.cache[key]
+	if !ok {
+		return "", false
+	}
+	r.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).html, true
+}
+
+// remember stores a rendered document, evicting the least recently used one when full
+func (r *Renderer) remember(key [sha256.Size]byte, html string) {
+	if r.size <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cache[key]; ok {
+		return // another goroutine rendered it at the same time
+	}
+	r.cache[key] = r.order.PushFront(&cacheEntry{key: key, html: html})
+	if r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.cache, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len reports how many documents are cached
+func (r *Renderer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
@@ -0,0 +1,103 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	cases := []struct {
+		name    string
+		src     string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "commonmark",
+			src:  "# Title\n\nSome *emphasis* and `code`.",
+			want: []string{"<h1>Title</h1>", "<em>emphasis</em>", "<code>code</code>"},
+		},
+		{
+			name: "gfm table and strikethrough",
+			src:  "| a | b |\n|---|---|\n| 1 | 2 |\n\n~~gone~~",
+			want: []string{"<table>", "<td>1</td>", "<del>gone</del>"},
+		},
+		{
+			name: "gfm autolink",
+			src:  "see https://go.dev",
+			want: []string{`<a href="https://go.dev" rel="nofollow">https://go.dev</a>`},
+		},
+		{
+			name: "task list",
+			src:  "- [x] done",
+			want: []string{`<input checked="" disabled="" type="checkbox"`},
+		},
+		{
+			name:    "code language is kept, other classes are not",
+			src:     "```go\nfmt.Println()\n```\n\n<p class=\"evil\">x</p>",
+			want:    []string{`<code class="language-go">`},
+			notWant: []string{"evil"},
+		},
+		{
+			name:    "script tags are stripped",
+			src:     "hello <script>alert(1)</script>",
+			want:    []string{"hello"},
+			notWant: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "event handlers are stripped",
+			src:     `<img src="x.png" onerror="alert(1)">`,
+			want:    []string{`<img src="x.png"`},
+			notWant: []string{"onerror"},
+		},
+		{
+			name:    "javascript links are stripped",
+			src:     "[click](javascript:alert(1))",
+			notWant: []string{"javascript:"},
+		},
+	}
+
+	r := NewRenderer(DefaultCacheSize)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Render(tc.src)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected %q in %q", want, got)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("did not expect %q in %q", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderer_Cache(t *testing.T) {
+	r := NewRenderer(2)
+
+	for i := range 3 {
+		r.Render("post " + strconv.Itoa(i))
+	}
+	if r.Len() != 2 {
+		t.Errorf("expected the cache to stay at 2 entries, got %d", r.Len())
+	}
+
+	first, _ := r.Render("**same**")
+	second, _ := r.Render("**same**")
+	if first != second {
+		t.Errorf("cached render differs: %q vs %q", first, second)
+	}
+
+	uncached := NewRenderer(0)
+	uncached.Render("anything")
+	if uncached.Len() != 0 {
+		t.Errorf("expected no caching, got %d entries", uncached.Len())
+	}
+}
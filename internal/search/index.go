@@ -0,0 +1,170 @@
+// Package search provides full-text search over posts.
+//
+// InvertedIndex is an in-memory index ranked with BM25. It is kept up to date by the
+// service layer, so it works on top of any store. Backends with their own full-text
+// search can skip it by implementing Searcher themselves.
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// Hit is a single search result, higher scores are more relevant
+type Hit struct {
+	PostID string
+	Score  float64
+}
+
+// Searcher answers full-text queries with the best hits first
+type Searcher interface {
+	Search(query string, limit int) ([]Hit, error)
+}
+
+// BM25 tuning, these are the usual textbook defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// titleBoost counts every title token this many times, a match in the title says more than one in the body
+	titleBoost = 3
+)
+
+// InvertedIndex maps every term to the posts containing it and how often.
+// It is safe for concurrent use.
+type InvertedIndex struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]int // term -> post id -> term frequency
+	docLen   map[string]int            // post id -> number of tokens
+	docTerms map[string][]string       // post id -> its distinct terms, so removing it only touches its postings
+	totalLen int
+}
+
+func NewInvertedIndex() *InvertedIndex {
+	return &InvertedIndex{
+		postings: make(map[string]map[string]int),
+		docLen:   make(map[string]int),
+		docTerms: make(map[string][]string),
+	}
+}
+
+// Add indexes a post, replacing whatever was indexed for its ID before
+func (idx *InvertedIndex) Add(post *models.Post) {
+	terms := map[string]int{}
+	length := 0
+	for _, token := range Tokenize(post.Name) {
+		terms[token] += titleBoost
+		length += titleBoost
+	}
+	for _, token := range Tokenize(post.Content) {
+		terms[token]++
+		length++
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(post.ID)
+	docTerms := make([]string, 0, len(terms))
+	for term, tf := range terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]int)
+		}
+		idx.postings[term][post.ID] = tf
+		docTerms = append(docTerms, term)
+	}
+	idx.docTerms[post.ID] = docTerms
+	idx.docLen[post.ID] = length
+	idx.totalLen += length
+}
+
+// Remove drops a post from the index, unknown IDs are ignored
+func (idx *InvertedIndex) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+// remove expects the write lock to be held
+func (idx *InvertedIndex) remove(id string) {
+	length, ok := idx.docLen[id]
+	if !ok {
+		return
+	}
+	for _, term := range idx.docTerms[id] {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docTerms, id)
+	delete(idx.docLen, id)
+	idx.totalLen -= length
+}
+
+// Reset empties the index
+func (idx *InvertedIndex) Reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.postings = make(map[string]map[string]int)
+	idx.docLen = make(map[string]int)
+	idx.docTerms = make(map[string][]string)
+	idx.totalLen = 0
+}
+
+// Search ranks every post containing at least one query term with BM25.
+// A limit <= 0 returns every hit.
+func (idx *InvertedIndex) Search(query string, limit int) ([]Hit, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docLen))
+	if n == 0 {
+		return []Hit{}, nil
+	}
+	avgLen := float64(idx.totalLen) / n
+
+	scores := map[string]float64{}
+	seen := map[string]bool{}
+	for _, term := range Tokenize(query) {
+		// repeating a word in the query shouldn't count it twice
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range docs {
+			f := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(idx.docLen[id])/avgLen
+			scores[id] += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{PostID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].PostID < hits[j].PostID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "lowercase and punctuation", text: "Hello, World! Go-lang", want: []string{"hello", "world", "go", "lang"}},
+		{name: "stop words dropped", text: "The art of the deal", want: []string{"art", "deal"}},
+		{name: "unicode letters kept", text: "Café naïve 2024", want: []string{"café", "naïve", "2024"}},
+		{name: "nothing left", text: "the and of", want: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Tokenize(tc.text)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Got %q wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func newPost(id, name, content string) *models.Post {
+	return &models.Post{ID: id, Name: name, Content: content}
+}
+
+func TestInvertedIndex_Search(t *testing.T) {
+	idx := NewInvertedIndex()
+	idx.Add(newPost("golang", "Learning Go", "Go has goroutines and channels. Channels are great."))
+	idx.Add(newPost("rust", "Learning Rust", "Rust has ownership and borrowing."))
+	idx.Add(newPost("cooking", "Pasta night", "Boil water, add pasta, mention go once."))
+
+	t.Run("ranks title matches and repeated terms higher", func(t *testing.T) {
+		hits, _ := idx.Search("go", 0)
+		if got := hitIDs(hits); !slices.Equal(got, []string{"golang", "cooking"}) {
+			t.Errorf("Got %v", got)
+		}
+	})
+
+	t.Run("multiple terms add up", func(t *testing.T) {
+		hits, _ := idx.Search("learning rust ownership", 0)
+		if got := hitIDs(hits); got[0] != "rust" {
+			t.Errorf("Expected rust first, got %v", got)
+		}
+	})
+
+	t.Run("case and stop words are ignored", func(t *testing.T) {
+		hits, _ := idx.Search("THE Channels", 0)
+		if got := hitIDs(hits); !slices.Equal(got, []string{"golang"}) {
+			t.Errorf("Got %v", got)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		hits, _ := idx.Search("learning", 1)
+		if len(hits) != 1 {
+			t.Errorf("Expected 1 hit, got %d", len(hits))
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		hits, err := idx.Search("python", 0)
+		if err != nil || len(hits) != 0 {
+			t.Errorf("Expected no hits, got %v %v", hits, err)
+		}
+	})
+}
+
+func TestInvertedIndex_Maintenance(t *testing.T) {
+	idx := NewInvertedIndex()
+	idx.Add(newPost("1", "Old title", "original words"))
+
+	// re-adding replaces the old terms
+	idx.Add(newPost("1", "New title", "different words"))
+	if hits, _ := idx.Search("original", 0); len(hits) != 0 {
+		t.Errorf("stale terms still indexed: %v", hits)
+	}
+	if hits, _ := idx.Search("different", 0); len(hits) != 1 {
+		t.Errorf("new terms not indexed: %v", hits)
+	}
+
+	idx.Remove("1")
+	if hits, _ := idx.Search("different", 0); len(hits) != 0 {
+		t.Errorf("removed post still found: %v", hits)
+	}
+	if len(idx.postings) != 0 || len(idx.docTerms) != 0 || idx.totalLen != 0 {
+		t.Errorf("remove left state behind: %v %v %d", idx.postings, idx.docTerms, idx.totalLen)
+	}
+
+	// removing one post leaves the postings it shared with another
+	idx.Add(newPost("3", "Shared", "common words"))
+	idx.Add(newPost("4", "Other", "common ground"))
+	idx.Remove("3")
+	if hits, _ := idx.Search("common", 0); len(hits) != 1 || hits[0].PostID != "4" {
+		t.Errorf("expected only post 4 for a shared term, got %v", hits)
+	}
+	if _, ok := idx.postings["shared"]; ok {
+		t.Errorf("removed post's own term still indexed: %v", idx.postings)
+	}
+	idx.Remove("4")
+
+	idx.Add(newPost("2", "Another", "post"))
+	idx.Reset()
+	if hits, _ := idx.Search("another", 0); len(hits) != 0 {
+		t.Errorf("reset index still returns hits: %v", hits)
+	}
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, h := range hits {
+		ids = append(ids, h.PostID)
+	}
+	return ids
+}
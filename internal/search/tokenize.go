@@ -0,0 +1,32 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are dropped from both documents and queries, they match almost everything
+// and would only add noise to the ranking
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "but": {}, "by": {},
+	"for": {}, "from": {}, "has": {}, "have": {}, "i": {}, "if": {}, "in": {}, "into": {}, "is": {},
+	"it": {}, "its": {}, "of": {}, "on": {}, "or": {}, "so": {}, "such": {}, "that": {}, "the": {},
+	"their": {}, "then": {}, "there": {}, "these": {}, "they": {}, "this": {}, "to": {}, "was": {},
+	"we": {}, "were": {}, "will": {}, "with": {}, "you": {},
+}
+
+// Tokenize lowercases text, splits it on anything that isn't a letter or digit and drops stop words
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if _, stop := stopWords[field]; stop {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type AuthorStore interface {
+	Create(context.Context, *models.Author) error
+	GetByID(context.Context, string) (*models.Author, error)
+	List(ctx context.Context) ([]*models.Author, error)
+}
+
+// AuthorServiceRepository handles business operations for authors
+// Design pattern: Dependency Injection - depends on store interface
+type AuthorServiceRepository struct {
+	Store AuthorStore
+}
+
+func NewAuthorService(store AuthorStore) *AuthorServiceRepository {
+	return &AuthorServiceRepository{
+		Store: store,
+	}
+}
+
+// CreateAuthor sets up the profile for id, which is the authenticated principal's ID.
+// Each principal gets exactly one profile, a second call fails with store.ErrAlreadyExists.
+func (s *AuthorServiceRepository) CreateAuthor(ctx context.Context, id, name, bio string) (*models.Author, error) {
+	// Business rule: sanitize name and bio
+	author, err := models.NewAuthor(id, strings.TrimSpace(name), strings.TrimSpace(bio))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Store.Create(ctx, author); err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+func (s *AuthorServiceRepository) GetAuthorByID(ctx context.Context, id string) (*models.Author, error) {
+	return s.Store.GetByID(ctx, id)
+}
+
+func (s *AuthorServiceRepository) ListAuthors(ctx context.Context) ([]*models.Author, error) {
+	return s.Store.List(ctx)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestAuthorService_CreateAuthor(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		author   string
+		wantErr  error
+		wantName string
+	}{
+		{name: "valid author", id: "alice", author: "  Alice  ", wantName: "Alice"},
+		{name: "blank name", id: "alice", author: "   ", wantErr: models.ErrEmptyAuthorName},
+		{name: "no principal", id: "", author: "Alice", wantErr: models.ErrEmptyAuthorID},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewAuthorService(store.NewInMemoryAuthorStore())
+
+			author, err := service.CreateAuthor(t.Context(), tc.id, tc.author, "")
+			AssertError(t, err, tc.wantErr)
+			if tc.wantErr == nil {
+				AssertTest(t, author.Name, tc.wantName)
+			}
+		})
+	}
+
+	t.Run("one profile per principal", func(t *testing.T) {
+		service := NewAuthorService(store.NewInMemoryAuthorStore())
+		_, err := service.CreateAuthor(t.Context(), "alice", "Alice", "")
+		AssertError(t, err, nil)
+		_, err = service.CreateAuthor(t.Context(), "alice", "Alice again", "")
+		AssertError(t, err, store.ErrAlreadyExists)
+	})
+}
+
+func TestPostService_CreatePost_WithAuthor(t *testing.T) {
+	mockStore := store.NewInMemoryStore()
+	service := NewPostService(mockStore)
+
+	post, err := service.CreatePost(t.Context(), "Authored post", "Written by alice", WithAuthor("alice"))
+	AssertError(t, err, nil)
+	_, err = service.CreatePost(t.Context(), "Anonymous post", "Nobody wrote this")
+	AssertError(t, err, nil)
+
+	stored, _ := mockStore.GetByID(t.Context(), post.ID)
+	AssertTest(t, stored.AuthorID, "alice")
+
+	page, err := service.ListPosts(t.Context(), models.PostQuery{AuthorID: "alice"})
+	AssertError(t, err, nil)
+	if len(page.Posts) != 1 || page.Posts[0].ID != post.ID {
+		t.Errorf("Expected only alice's post, got %d posts", len(page.Posts))
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+var (
+	ErrCommentTooShort  = errors.New("Comment Too Short, must be at least contain 2 chars")
+	ErrDuplicateComment = errors.New("Same comment already posted on this post")
+	ErrInvalidParent    = errors.New("Parent comment does not belong to this post")
+)
+
+type CommentStore interface {
+	Create(context.Context, *models.Comment) error
+	GetByID(context.Context, string) (*models.Comment, error)
+	// ListByPost returns every comment on the post oldest first, replies included
+	ListByPost(ctx context.Context, postID string) ([]*models.Comment, error)
+	DeleteByPost(ctx context.Context, postID string) error
+	DeleteAll(ctx context.Context) error
+}
+
+// CommentServiceRepository handles business operations for comments
+// Design pattern: Dependency Injection - depends on the comment and post store interfaces
+type CommentServiceRepository struct {
+	Store CommentStore
+	Posts PostStore
+}
+
+func NewCommentService(store CommentStore, posts PostStore) *CommentServiceRepository {
+	return &CommentServiceRepository{
+		Store: store,
+		Posts: posts,
+	}
+}
+
+// GetPost returns the post comments are on, the handlers check who may see it with it
+func (s *CommentServiceRepository) GetPost(ctx context.Context, postID string) (*models.Post, error) {
+	return s.Posts.GetByID(ctx, postID)
+}
+
+// CreateComment adds a comment to a post, or a reply when parentID is set.
+// The rules mirror CreatePost: sanitize, check the length, reject duplicates.
+func (s *CommentServiceRepository) CreateComment(ctx context.Context, postID, parentID, authorID, body string) (*models.Comment, error) {
+	// the post has to exist, store.ErrNotFound otherwise
+	if _, err := s.Posts.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	// Business rule 1: sanitize body
+	trimmedBody := strings.TrimSpace(body)
+
+	// Business rule 2: validate the body, empty is left to the domain validation
+	if trimmedBody != "" && len(trimmedBody) < 2 {
+		return nil, ErrCommentTooShort
+	}
+
+	// Business rule 3: replies must stay within the same post
+	if parentID != "" {
+		parent, err := s.Store.GetByID(ctx, parentID)
+		if err != nil || parent.PostID != postID {
+			return nil, ErrInvalidParent
+		}
+	}
+
+	existing, err := s.Store.ListByPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Business rule 4: the same author posting the same text twice is almost always a double submit
+	for _, c := range existing {
+		if c.AuthorID == authorID && strings.EqualFold(c.Body, trimmedBody) {
+			return nil, ErrDuplicateComment
+		}
+	}
+
+	// Create the comment (using domain validation)
+	comment, err := models.NewComment(postID, parentID, authorID, trimmedBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Store.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// CommentThread is a comment with its replies nested underneath
+type CommentThread struct {
+	Comment *models.Comment
+	Replies []*CommentThread
+}
+
+// ListThreads returns the comments on a post as a forest of threads, oldest first at every level
+func (s *CommentServiceRepository) ListThreads(ctx context.Context, postID string) ([]*CommentThread, error) {
+	if _, err := s.Posts.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.Store.ListByPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*CommentThread, len(comments))
+	for _, c := range comments {
+		nodes[c.ID] = &CommentThread{Comment: c, Replies: []*CommentThread{}}
+	}
+
+	// comments come oldest first, so appending keeps every level in order
+	roots := []*CommentThread{}
+	for _, c := range comments {
+		node := nodes[c.ID]
+		if parent, ok := nodes[c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestCommentService_CreateComment(t *testing.T) {
+	postStore := store.NewInMemoryStore()
+	commentStore := store.NewInMemoryCommentStore()
+	posts := NewPostService(postStore, WithCommentStore(commentStore))
+	comments := NewCommentService(commentStore, postStore)
+
+	post, err := posts.CreatePost(t.Context(), "Commented post", "Something to talk about")
+	AssertError(t, err, nil)
+	other, err := posts.CreatePost(t.Context(), "Other post", "Something else")
+	AssertError(t, err, nil)
+
+	top, err := comments.CreateComment(t.Context(), post.ID, "", "alice", "  Nice post!  ")
+	AssertError(t, err, nil)
+	AssertTest(t, top.Body, "Nice post!")
+
+	elsewhere, err := comments.CreateComment(t.Context(), other.ID, "", "alice", "Over here")
+	AssertError(t, err, nil)
+
+	tests := []struct {
+		name     string
+		postID   string
+		parentID string
+		author   string
+		body     string
+		wantErr  error
+	}{
+		{name: "reply", postID: post.ID, parentID: top.ID, author: "bob", body: "Agreed"},
+		{name: "unknown post", postID: "nope", author: "bob", body: "Hello there", wantErr: store.ErrNotFound},
+		{name: "empty body", postID: post.ID, author: "bob", body: "   ", wantErr: models.ErrEmptyComment},
+		{name: "too short", postID: post.ID, author: "bob", body: "k", wantErr: ErrCommentTooShort},
+		{name: "double submit", postID: post.ID, author: "alice", body: "nice POST!", wantErr: ErrDuplicateComment},
+		{name: "same text from someone else", postID: post.ID, author: "carol", body: "Nice post!"},
+		{name: "unknown parent", postID: post.ID, parentID: "nope", author: "bob", body: "Hello there", wantErr: ErrInvalidParent},
+		{name: "parent on another post", postID: post.ID, parentID: elsewhere.ID, author: "bob", body: "Hello there", wantErr: ErrInvalidParent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := comments.CreateComment(t.Context(), tc.postID, tc.parentID, tc.author, tc.body)
+			AssertError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestCommentService_ListThreads(t *testing.T) {
+	postStore := store.NewInMemoryStore()
+	commentStore := store.NewInMemoryCommentStore()
+	posts := NewPostService(postStore, WithCommentStore(commentStore))
+	comments := NewCommentService(commentStore, postStore)
+
+	post, _ := posts.CreatePost(t.Context(), "Threaded post", "Let the threads begin")
+	first, _ := comments.CreateComment(t.Context(), post.ID, "", "alice", "First!")
+	reply, _ := comments.CreateComment(t.Context(), post.ID, first.ID, "bob", "Reply to first")
+	comments.CreateComment(t.Context(), post.ID, reply.ID, "carol", "Reply to reply")
+	comments.CreateComment(t.Context(), post.ID, "", "dave", "Second top level")
+
+	threads, err := comments.ListThreads(t.Context(), post.ID)
+	AssertError(t, err, nil)
+
+	if len(threads) != 2 {
+		t.Fatalf("Expected 2 top level threads, got %d", len(threads))
+	}
+	AssertTest(t, threads[0].Comment.Body, "First!")
+	AssertTest(t, threads[1].Comment.Body, "Second top level")
+	if len(threads[0].Replies) != 1 || len(threads[0].Replies[0].Replies) != 1 {
+		t.Fatalf("Expected a two level thread under the first comment")
+	}
+	AssertTest(t, threads[0].Replies[0].Replies[0].Comment.Body, "Reply to reply")
+
+	_, err = comments.ListThreads(t.Context(), "nope")
+	AssertError(t, err, store.ErrNotFound)
+}
+
+func TestPostService_PurgeCascadesToComments(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	postStore := store.NewInMemoryStore()
+	commentStore := store.NewInMemoryCommentStore()
+	posts := NewPostService(postStore, WithCommentStore(commentStore), WithClock(func() time.Time { return now }))
+	comments := NewCommentService(commentStore, postStore)
+
+	doomed, _ := posts.CreatePost(t.Context(), "Doomed", "Will be deleted")
+	kept, _ := posts.CreatePost(t.Context(), "Kept", "Survives single delete")
+	comments.CreateComment(t.Context(), doomed.ID, "", "alice", "Goodbye")
+	comments.CreateComment(t.Context(), kept.ID, "", "alice", "Still here")
+
+	// deleting only trashes the post, its comments wait for the purge
+	AssertError(t, posts.DeletePost(t.Context(), doomed.ID), nil)
+	if left, _ := commentStore.ListByPost(t.Context(), doomed.ID); len(left) != 1 {
+		t.Errorf("Expected comments of a trashed post to stay, got %d", len(left))
+	}
+
+	now = now.Add(time.Hour)
+	n, err := posts.PurgeTrash(t.Context(), time.Minute)
+	AssertError(t, err, nil)
+	if n != 1 {
+		t.Errorf("Expected 1 purged post, got %d", n)
+	}
+	if left, _ := commentStore.ListByPost(t.Context(), doomed.ID); len(left) != 0 {
+		t.Errorf("Expected comments of purged post to be gone, got %d", len(left))
+	}
+	if left, _ := commentStore.ListByPost(t.Context(), kept.ID); len(left) != 1 {
+		t.Errorf("Purge removed comments of another post")
+	}
+
+	AssertError(t, posts.DeleteAll(t.Context()), nil)
+	now = now.Add(time.Hour)
+	posts.PurgeTrash(t.Context(), time.Minute)
+	if left, _ := commentStore.ListByPost(t.Context(), kept.ID); len(left) != 0 {
+		t.Errorf("Expected purging everything to remove every comment, got %d", len(left))
+	}
+}
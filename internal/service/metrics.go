@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gaugeTimeout bounds the store queries behind the gauges, a scrape has no request context
+const gaugeTimeout = 5 * time.Second
+
+// InstrumentedPostStore counts every call to the store it wraps, and every failed one, by operation.
+// Design pattern: Decorator - the service keeps talking to a PostStore and never knows it is there
+type InstrumentedPostStore struct {
+	PostStore
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+}
+
+// NewInstrumentedPostStore wraps next and registers goblog_store_operations_total and
+// goblog_store_errors_total in reg
+func NewInstrumentedPostStore(next PostStore, reg prometheus.Registerer) *InstrumentedPostStore {
+	s := &InstrumentedPostStore{
+		PostStore: next,
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goblog_store_operations_total",
+			Help: "Post store calls by operation.",
+		}, []string{"op"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goblog_store_errors_total",
+			Help: "Post store calls that failed, by operation. Not found and conflicts are not failures.",
+		}, []string{"op"}),
+	}
+	reg.MustRegister(s.operations, s.errors)
+	return s
+}
+
+// unwrapStore returns the store an InstrumentedPostStore wraps, any other store as it is.
+// Methods outside PostStore, like search.Searcher's Search, are only found on the wrapped store.
+func unwrapStore(postStore PostStore) PostStore {
+	if instrumented, ok := postStore.(*InstrumentedPostStore); ok {
+		return instrumented.PostStore
+	}
+	return postStore
+}
+
+// observe counts one call. Answers like not found are part of normal operation and a client
+// that went away is not the store's fault, neither counts as an error.
+func (s *InstrumentedPostStore) observe(op string, err error) error {
+	s.operations.WithLabelValues(op).Inc()
+	switch {
+	case err == nil,
+		errors.Is(err, store.ErrNotFound),
+		errors.Is(err, store.ErrEmptyStore),
+		errors.Is(err, store.ErrAlreadyExists),
+		errors.Is(err, context.Canceled):
+	default:
+		s.errors.WithLabelValues(op).Inc()
+	}
+	return err
+}
+
+func (s *InstrumentedPostStore) Create(ctx context.Context, post *models.Post) error {
+	return s.observe("create", s.PostStore.Create(ctx, post))
+}
+
+func (s *InstrumentedPostStore) CreateWithRevision(ctx context.Context, post *models.Post, rev *models.Revision) error {
+	return s.observe("create", s.PostStore.CreateWithRevision(ctx, post, rev))
+}
+
+func (s *InstrumentedPostStore) UpdateWithRevision(ctx context.Context, post *models.Post, rev *models.Revision) error {
+	return s.observe("update", s.PostStore.UpdateWithRevision(ctx, post, rev))
+}
+
+func (s *InstrumentedPostStore) GetAll(ctx context.Context) ([]*models.Post, error) {
+	posts, err := s.PostStore.GetAll(ctx)
+	return posts, s.observe("get_all", err)
+}
+
+func (s *InstrumentedPostStore) FindByTitle(ctx context.Context, title string) ([]*models.Post, error) {
+	posts, err := s.PostStore.FindByTitle(ctx, title)
+	return posts, s.observe("find_by_title", err)
+}
+
+func (s *InstrumentedPostStore) GetByID(ctx context.Context, id string) (*models.Post, error) {
+	post, err := s.PostStore.GetByID(ctx, id)
+	return post, s.observe("get_by_id", err)
+}
+
+func (s *InstrumentedPostStore) GetBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	post, err := s.PostStore.GetBySlug(ctx, slug)
+	return post, s.observe("get_by_slug", err)
+}
+
+func (s *InstrumentedPostStore) Update(ctx context.Context, post *models.Post) error {
+	return s.observe("update", s.PostStore.Update(ctx, post))
+}
+
+func (s *InstrumentedPostStore) List(ctx context.Context, q models.PostQuery) ([]*models.Post, error) {
+	posts, err := s.PostStore.List(ctx, q)
+	return posts, s.observe("list", err)
+}
+
+func (s *InstrumentedPostStore) Delete(ctx context.Context, id string) error {
+	return s.observe("delete", s.PostStore.Delete(ctx, id))
+}
+
+func (s *InstrumentedPostStore) DeleteAll(ctx context.Context) error {
+	return s.observe("delete_all", s.PostStore.DeleteAll(ctx))
+}
+
+func (s *InstrumentedPostStore) Trash(ctx context.Context, id string, at time.Time) error {
+	return s.observe("trash", s.PostStore.Trash(ctx, id, at))
+}
+
+func (s *InstrumentedPostStore) TrashAll(ctx context.Context, at time.Time) error {
+	return s.observe("trash_all", s.PostStore.TrashAll(ctx, at))
+}
+
+func (s *InstrumentedPostStore) Restore(ctx context.Context, id string) error {
+	return s.observe("restore", s.PostStore.Restore(ctx, id))
+}
+
+func (s *InstrumentedPostStore) GetTrashed(ctx context.Context, id string) (*models.Post, error) {
+	post, err := s.PostStore.GetTrashed(ctx, id)
+	return post, s.observe("get_trashed", err)
+}
+
+func (s *InstrumentedPostStore) ListTrash(ctx context.Context, q models.TrashQuery) ([]*models.Post, error) {
+	posts, err := s.PostStore.ListTrash(ctx, q)
+	return posts, s.observe("list_trash", err)
+}
+
+func (s *InstrumentedPostStore) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	ids, err := s.PostStore.Purge(ctx, deletedBefore)
+	return ids, s.observe("purge", err)
+}
+
+func (s *InstrumentedPostStore) TagCounts(ctx context.Context) ([]models.TagCount, error) {
+	tags, err := s.PostStore.TagCounts(ctx)
+	return tags, s.observe("tag_counts", err)
+}
+
+func (s *InstrumentedPostStore) Counts(ctx context.Context) (models.PostCounts, error) {
+	counts, err := s.PostStore.Counts(ctx)
+	return counts, s.observe("counts", err)
+}
+
+// RegisterMetrics adds gauges for the number of posts, read from the store on every scrape:
+// goblog_posts by status for live posts and goblog_posts_trashed for the trash
+func (s *PostServiceRepository) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(&postCountsCollector{
+		// the gauges' own reads would otherwise show up as store operations on every scrape
+		store:   unwrapStore(s.Store),
+		posts:   prometheus.NewDesc("goblog_posts", "Live posts by status.", []string{"status"}, nil),
+		trashed: prometheus.NewDesc("goblog_posts_trashed", "Posts in the trash waiting to be purged.", nil, nil),
+	})
+}
+
+// postCountsCollector reads the store's Counts once per scrape and turns them into both gauges
+type postCountsCollector struct {
+	store   PostStore
+	posts   *prometheus.Desc
+	trashed *prometheus.Desc
+}
+
+func (c *postCountsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.posts
+	ch <- c.trashed
+}
+
+func (c *postCountsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), gaugeTimeout)
+	defer cancel()
+
+	counts, err := c.store.Counts(ctx)
+	if err != nil {
+		// the scrape fails loudly instead of reporting an empty blog
+		ch <- prometheus.NewInvalidMetric(c.posts, err)
+		ch <- prometheus.NewInvalidMetric(c.trashed, err)
+		return
+	}
+	// every status is always there, a status nobody uses is a 0 instead of a missing series
+	for _, status := range models.Statuses {
+		ch <- prometheus.MustNewConstMetric(c.posts, prometheus.GaugeValue, float64(counts.ByStatus[status]), string(status))
+	}
+	ch <- prometheus.MustNewConstMetric(c.trashed, prometheus.GaugeValue, float64(counts.Trashed))
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// failingStore fails every update the way a broken database would
+type failingStore struct {
+	*store.InMemoryStore
+}
+
+func (failingStore) UpdateWithRevision(context.Context, *models.Post, *models.Revision) error {
+	return errors.New("disk full")
+}
+
+func TestInstrumentedPostStore(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	instrumented := NewInstrumentedPostStore(failingStore{store.NewInMemoryStore()}, reg)
+	service := NewPostService(instrumented)
+
+	post, err := service.CreatePost(t.Context(), "Counted", "Every store call is counted")
+	AssertError(t, err, nil)
+
+	// not found is an answer, not a failure
+	_, err = service.GetPostByID(t.Context(), "nope")
+	AssertError(t, err, store.ErrNotFound)
+
+	title := "Never saved"
+	_, err = service.UpdatePost(t.Context(), post.ID, "", &title, nil)
+	if err == nil {
+		t.Fatal("Expected the update to fail")
+	}
+
+	tests := []struct {
+		op                string
+		wantOps, wantErrs float64
+	}{
+		{op: "create", wantOps: 1},
+		{op: "get_by_id", wantOps: 2},
+		{op: "update", wantOps: 1, wantErrs: 1},
+	}
+	for _, tc := range tests {
+		if got := testutil.ToFloat64(instrumented.operations.WithLabelValues(tc.op)); got != tc.wantOps {
+			t.Errorf("%s operations: got %v want %v", tc.op, got, tc.wantOps)
+		}
+		if got := testutil.ToFloat64(instrumented.errors.WithLabelValues(tc.op)); got != tc.wantErrs {
+			t.Errorf("%s errors: got %v want %v", tc.op, got, tc.wantErrs)
+		}
+	}
+}
+
+// countingStore counts Counts calls, a scrape should make exactly one
+type countingStore struct {
+	*store.InMemoryStore
+	calls int
+}
+
+func (s *countingStore) Counts(ctx context.Context) (models.PostCounts, error) {
+	s.calls++
+	return s.InMemoryStore.Counts(ctx)
+}
+
+func TestPostService_RegisterMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	counting := &countingStore{InMemoryStore: store.NewInMemoryStore()}
+	instrumented := NewInstrumentedPostStore(counting, reg)
+	service := NewPostService(instrumented)
+	for _, title := range []string{"One", "Two", "Three"} {
+		_, err := service.CreatePost(t.Context(), title, "Some content", WithStatus(models.StatusDraft))
+		AssertError(t, err, nil)
+	}
+	post, err := service.CreatePost(t.Context(), "Binned", "Some content")
+	AssertError(t, err, nil)
+	AssertError(t, service.DeletePost(t.Context(), post.ID), nil)
+
+	service.RegisterMetrics(reg)
+
+	want := `
+# HELP goblog_posts Live posts by status.
+# TYPE goblog_posts gauge
+goblog_posts{status="archived"} 0
+goblog_posts{status="draft"} 3
+goblog_posts{status="published"} 0
+goblog_posts{status="scheduled"} 0
+# HELP goblog_posts_trashed Posts in the trash waiting to be purged.
+# TYPE goblog_posts_trashed gauge
+goblog_posts_trashed 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "goblog_posts", "goblog_posts_trashed"); err != nil {
+		t.Error(err)
+	}
+	if counting.calls != 1 {
+		t.Errorf("Expected one Counts call per scrape, got %d", counting.calls)
+	}
+	// the scrape reads the store underneath, it is not a store operation of the blog
+	if got := testutil.ToFloat64(instrumented.operations.WithLabelValues("counts")); got != 0 {
+		t.Errorf("Expected the scrape not to be counted as an operation, got %v", got)
+	}
+}
@@ -1,101 +1,903 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/aziz-shoko/goblog/internal/diff"
+	"github.com/aziz-shoko/goblog/internal/markdown"
+	"github.com/aziz-shoko/goblog/internal/search"
+	"github.com/aziz-shoko/goblog/internal/store"
 	"github.com/aziz-shoko/goblog/models"
 )
 
 var (
-	ErrContentTooShort = errors.New("Content Too Short, must be at least contain 5 chars")
-	ErrDuplicateTitle  = errors.New("Title already exists (case insensitive)")
+	ErrContentTooShort  = errors.New("Content Too Short")
+	ErrDuplicateTitle   = errors.New("Title already exists (case insensitive)")
+	ErrEmptyQuery       = errors.New("search query cannot be empty")
+	ErrInvalidTag       = errors.New("tags may only contain letters, digits and dashes, up to 32 chars")
+	ErrTooManyTags      = errors.New("a post can have at most 10 tags")
+	ErrInvalidCategory  = errors.New("category may only contain letters, digits and dashes, up to 32 chars")
+	ErrMissingPublishAt = errors.New("scheduling a post needs a publish_at in the future")
+	ErrNoRevisions      = errors.New("the post store keeps no revision history")
 )
+
+const (
+	maxTags      = 10
+	maxTagLength = 32
+
+	// DefaultMinContentLength is how many chars a post needs unless WithMinContentLength says otherwise
+	DefaultMinContentLength = 5
+)
+
 type PostStore interface {
-	Create(*models.Post) error
-	GetAll() ([]*models.Post, error)
-	GetByID(string) (*models.Post, error)
-	// Delete(id string) error
-	DeleteAll() error
+	Create(context.Context, *models.Post) error
+	// CreateWithRevision and UpdateWithRevision save the post and, unless it is nil, the revision
+	// in one transaction, the revision lands in the store's own revision store
+	CreateWithRevision(context.Context, *models.Post, *models.Revision) error
+	UpdateWithRevision(context.Context, *models.Post, *models.Revision) error
+	GetAll(ctx context.Context) ([]*models.Post, error)
+	// FindByTitle returns the live posts with this title, ignoring case
+	FindByTitle(ctx context.Context, title string) ([]*models.Post, error)
+	GetByID(context.Context, string) (*models.Post, error)
+	// GetBySlug also finds posts by a slug they had before a rename, and trashed posts
+	GetBySlug(context.Context, string) (*models.Post, error)
+	Update(context.Context, *models.Post) error
+	// List returns one page of posts matching the query, an empty page is not an error
+	List(context.Context, models.PostQuery) ([]*models.Post, error)
+	// Delete and DeleteAll remove posts for good, the service trashes them instead
+	Delete(ctx context.Context, id string) error
+	DeleteAll(ctx context.Context) error
+	// Trash hides a post until it is restored or purged, reads other than GetBySlug, GetTrashed and ListTrash skip it
+	Trash(ctx context.Context, id string, at time.Time) error
+	TrashAll(ctx context.Context, at time.Time) error
+	Restore(ctx context.Context, id string) error
+	// GetTrashed finds one trashed post by id, live posts are not found
+	GetTrashed(ctx context.Context, id string) (*models.Post, error)
+	// ListTrash returns one page of trashed posts, most recently deleted first
+	ListTrash(ctx context.Context, q models.TrashQuery) ([]*models.Post, error)
+	// Purge deletes posts trashed before deletedBefore for good and returns their ids
+	Purge(ctx context.Context, deletedBefore time.Time) ([]string, error)
+	// TagCounts returns every tag in use with its number of posts, most used first
+	TagCounts(ctx context.Context) ([]models.TagCount, error)
+	// Counts returns how many live posts there are in each status, and how many are trashed
+	Counts(ctx context.Context) (models.PostCounts, error)
+}
+
+// RevisionStore keeps the history of a post's title and content, revisions are never changed once created
+type RevisionStore interface {
+	// Create numbers the revision after the post's latest one
+	Create(context.Context, *models.Revision) error
+	// ListByPost returns the post's revisions oldest first
+	ListByPost(ctx context.Context, postID string) ([]*models.Revision, error)
+	GetByNumber(ctx context.Context, postID string, number int) (*models.Revision, error)
+	DeleteByPost(ctx context.Context, postID string) error
+	DeleteAll(ctx context.Context) error
+}
+
+// SearchIndex is a searcher the service has to keep up to date itself on every write,
+// search.InvertedIndex is the default one
+type SearchIndex interface {
+	search.Searcher
+	Add(*models.Post)
+	Remove(id string)
+	Reset()
 }
 
 // Post service handles business operations for blog posts
 // Design pattern: Dependency Injection - depends on store interface
 type PostServiceRepository struct {
 	Store PostStore
+
+	// Searcher answers GET /posts/search, index is only set when the service owns it
+	Searcher search.Searcher
+	index    SearchIndex
+
+	// Comments is optional, when set deleting posts also deletes their comments
+	Comments CommentStore
+
+	// Revisions is where the post store's CreateWithRevision and UpdateWithRevision put the history,
+	// nil for a store that keeps none, then reading revisions is ErrNoRevisions
+	Revisions RevisionStore
+
+	// Renderer fills in Post.ContentHTML on every post the service hands out
+	Renderer ContentRenderer
+
+	// policy is the rules titles and content are checked against
+	policy ValidationPolicy
+
+	// clock is time.Now outside of tests
+	clock func() time.Time
+}
+
+// ContentRenderer turns post content into HTML that is safe to serve,
+// markdown.Renderer is the default one
+type ContentRenderer interface {
+	Render(content string) (string, error)
+}
+
+// Option configures optional parts of the service
+// Design pattern: Functional Options - NewPostService(store) keeps working as more knobs are added
+type Option func(*PostServiceRepository)
+
+// WithSearcher plugs in an external full-text search, e.g. one backed by the database's own FTS.
+// The service won't maintain an index of its own then.
+func WithSearcher(searcher search.Searcher) Option {
+	return func(s *PostServiceRepository) {
+		s.Searcher = searcher
+	}
+}
+
+// WithCommentStore makes post deletion cascade to the post's comments
+func WithCommentStore(comments CommentStore) Option {
+	return func(s *PostServiceRepository) {
+		s.Comments = comments
+	}
+}
+
+// WithRevisionStore reads the history from revisions, for post stores outside internal/store.
+// It has to be where the post store's CreateWithRevision and UpdateWithRevision write.
+func WithRevisionStore(revisions RevisionStore) Option {
+	return func(s *PostServiceRepository) {
+		s.Revisions = revisions
+	}
+}
+
+// WithRenderer replaces the default markdown renderer
+func WithRenderer(renderer ContentRenderer) Option {
+	return func(s *PostServiceRepository) {
+		s.Renderer = renderer
+	}
+}
+
+// WithValidationPolicy replaces DefaultValidationPolicy, so deployments can tune the rules
+func WithValidationPolicy(policy ValidationPolicy) Option {
+	return func(s *PostServiceRepository) {
+		s.policy = policy
+	}
+}
+
+// WithClock replaces time.Now for publishing decisions, tests use it to move time along
+func WithClock(clock func() time.Time) Option {
+	return func(s *PostServiceRepository) {
+		s.clock = clock
+	}
 }
 
 // NewPostService creates a new post service
 // Design pattern: Dependency Injection - inject the store dependency
-func NewPostService(store PostStore) *PostServiceRepository {
-	return &PostServiceRepository{
-		Store: store,
+// A store that implements search.Searcher itself is used for search, otherwise the service
+// builds an in-memory inverted index from whatever the store already holds.
+func NewPostService(postStore PostStore, opts ...Option) *PostServiceRepository {
+	s := &PostServiceRepository{
+		Store:     postStore,
+		Revisions: revisionsOf(postStore),
+		Renderer:  markdown.NewRenderer(markdown.DefaultCacheSize),
+
+		policy: DefaultValidationPolicy(),
+		clock:  time.Now,
+	}
+	// the metrics wrapper only has the PostStore methods, the search is the wrapped store's
+	if searcher, ok := unwrapStore(postStore).(search.Searcher); ok {
+		s.Searcher = searcher
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.Searcher == nil {
+		index := search.NewInvertedIndex()
+		posts, _ := postStore.GetAll(context.Background()) // an empty store is not a problem here, this runs once at startup
+		for _, post := range posts {
+			index.Add(post)
+		}
+		s.Searcher = index
+		s.index = index
+	}
+
+	return s
+}
+
+// revisionsOf returns the revision store that postStore writes revisions to, every store in
+// internal/store has one next to its posts. Other stores get nil unless WithRevisionStore is used.
+func revisionsOf(postStore PostStore) RevisionStore {
+	switch st := unwrapStore(postStore).(type) {
+	case interface {
+		Revisions() *store.SQLRevisionStore
+	}:
+		return st.Revisions()
+	case interface {
+		Revisions() *store.InMemoryRevisionStore
+	}:
+		return st.Revisions()
+	}
+	return nil
+}
+
+// PostOption sets optional fields on a post before it is validated and stored
+type PostOption func(*models.Post)
+
+// WithAuthor links the new post to the author writing it
+func WithAuthor(authorID string) PostOption {
+	return func(p *models.Post) {
+		p.AuthorID = authorID
+	}
+}
+
+// WithTags tags the new post, tags are normalized by CreatePost
+func WithTags(tags ...string) PostOption {
+	return func(p *models.Post) {
+		p.Tags = tags
+	}
+}
+
+// WithCategory files the new post under a category, normalized like a tag
+func WithCategory(category string) PostOption {
+	return func(p *models.Post) {
+		p.Category = category
+	}
+}
+
+// WithStatus creates the post as a draft, scheduled, ... instead of published
+func WithStatus(status models.PostStatus) PostOption {
+	return func(p *models.Post) {
+		p.Status = status
+	}
+}
+
+// WithPublishAt sets when the post goes public, a time in the future schedules it
+func WithPublishAt(at time.Time) PostOption {
+	return func(p *models.Post) {
+		p.PublishAt = at
 	}
 }
 
 // CreatePost creates a new blog post with business rule validation
-func (s *PostServiceRepository) CreatePost(title, content string) (*models.Post, error) {
+func (s *PostServiceRepository) CreatePost(ctx context.Context, title, content string, opts ...PostOption) (*models.Post, error) {
 	// Business rule 1: sanitize title
 	trimmedTitle := strings.TrimSpace(title)
 
-	// Business rule 2: validate the title and content
-	if len(content) < 5 {
-		return nil, ErrContentTooShort
+	// the options run once, on the post being built, so the policy knows the author
+	post := &models.Post{}
+	for _, opt := range opts {
+		opt(post)
 	}
 
-	// Business rule 3
-	if s.titleExists(title) {
-		return nil, ErrDuplicateTitle
+	// Business rules 2 and 3: the policy checks the title and content, duplicate titles included,
+	// and reports every failure at once
+	if err := s.validatePost(ctx, trimmedTitle, content, "", post.AuthorID); err != nil {
+		slog.DebugContext(ctx, "post rejected", "error", err)
+		return nil, err
 	}
 
-	// Create the post (using domain validation)
-	post, err := models.NewPost(trimmedTitle, content)
+	// domain validation, the post takes its id, title, content and slug from NewPost
+	created, err := models.NewPost(trimmedTitle, content)
 	if err != nil {
 		return nil, err
 	}
+	post.ID, post.Name, post.Content, post.Slug = created.ID, created.Name, created.Content, created.Slug
+	// the service's clock decides the timestamps, PublishAt is left for the options and rule 5
+	now := s.clock().UTC()
+	post.CreatedAt, post.UpdatedAt = now, now
 
-	// store the post
-	err = s.Store.Create(post)
-	if err != nil {
+	// Business rule 4: every post gets its own slug, never one another post uses or used
+	if post.Slug, err = s.uniqueSlug(ctx, post.Slug, post.ID); err != nil {
+		return nil, err
+	}
+
+	// Business rule 5: only published posts are public, and scheduled ones need to know when
+	if err := applyStatus(post, now); err != nil {
+		return nil, err
+	}
+
+	// Business rule 6: tags and category are lowercase slugs so "Go" and "go " are the same tag
+	if post.Tags, err = normalizeTags(post.Tags); err != nil {
+		return nil, err
+	}
+	if post.Category != "" {
+		if post.Category, err = normalizeTag(post.Category); err != nil {
+			return nil, ErrInvalidCategory
+		}
+	}
+
+	// store the post, its history starts with the post as it was created, written by its author
+	if err := s.Store.CreateWithRevision(ctx, post, models.NewRevision(post, post.AuthorID)); err != nil {
 		return nil, err
 	}
 
+	if s.index != nil {
+		s.index.Add(post)
+	}
+	slog.InfoContext(ctx, "post created", "post_id", post.ID, "author_id", post.AuthorID, "status", post.Status)
+
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
-func (s *PostServiceRepository) GetPostByID(id string) (*models.Post, error) {
-	post, err := s.Store.GetByID(id)
+func (s *PostServiceRepository) GetPostByID(ctx context.Context, id string) (*models.Post, error) {
+	post, err := s.Store.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
-func (s *PostServiceRepository) ListAllPosts() ([]*models.Post, error) {
-	posts, err := s.Store.GetAll()
+// GetPostBySlug finds a post by its current or a previous slug, compare the result's Slug to tell them apart
+func (s *PostServiceRepository) GetPostBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	post, err := s.Store.GetBySlug(ctx, slug)
 	if err != nil {
 		return nil, err
 	}
+	if !post.DeletedAt.IsZero() {
+		return nil, store.ErrNotFound
+	}
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+func (s *PostServiceRepository) ListAllPosts(ctx context.Context) ([]*models.Post, error) {
+	posts, err := s.Store.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.renderHTML(posts...); err != nil {
+		return nil, err
+	}
 	return posts, nil
 }
 
-func (s *PostServiceRepository) titleExists(title string) bool {
-	posts, err := s.Store.GetAll()
+// ListPosts returns one page of posts.
+// The store is asked for one extra post so we know whether there is a next page without a COUNT query.
+func (s *PostServiceRepository) ListPosts(ctx context.Context, q models.PostQuery) (*models.PostPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	q.Limit++
+	posts, err := s.Store.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PostPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		page.HasMore = true
+	}
+	if err := s.renderHTML(page.Posts...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// UpdatePost edits an existing post with the same business rules as CreatePost.
+// A nil title or content keeps the current value, which is what PATCH needs; PUT passes both.
+// Every edit that changes the title or content is recorded as a revision by editorID.
+func (s *PostServiceRepository) UpdatePost(ctx context.Context, id, editorID string, title, content *string) (*models.Post, error) {
+	post, err := s.Store.GetByID(ctx, id)
 	if err != nil {
-		return false // if we cant check, return false
+		return nil, err
 	}
 
+	newTitle, newContent := post.Name, post.Content
+	if title != nil {
+		// Business rule 1: sanitize title
+		newTitle = strings.TrimSpace(*title)
+	}
+	if content != nil {
+		newContent = *content
+	}
+
+	// Business rules 2 and 3: same policy as CreatePost, the post may keep its own title
+	if err := s.validatePost(ctx, newTitle, newContent, post.ID, post.AuthorID); err != nil {
+		slog.DebugContext(ctx, "post update rejected", "post_id", post.ID, "error", err)
+		return nil, err
+	}
+
+	// domain validation
+	oldTitle, oldContent, oldSlug := post.Name, post.Content, post.Slug
+	// the service's clock decides UpdatedAt and so the new revision's CreatedAt
+	if err := post.Edit(newTitle, newContent, s.clock()); err != nil {
+		return nil, err
+	}
+
+	// Business rule 4: the slug only moves with the title, the store keeps the old one for redirects
+	if newTitle == oldTitle {
+		post.Slug = oldSlug
+	} else if post.Slug, err = s.uniqueSlug(ctx, post.Slug, post.ID); err != nil {
+		return nil, err
+	}
+
+	// Business rule 5: saving the same title and content again is not a new revision
+	var rev *models.Revision
+	changed := post.Name != oldTitle || post.Content != oldContent
+	if changed {
+		rev = models.NewRevision(post, editorID)
+	}
+	if err := s.Store.UpdateWithRevision(ctx, post, rev); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "post updated", "post_id", post.ID, "editor_id", editorID, "new_revision", changed)
+
+	if s.index != nil {
+		s.index.Add(post)
+	}
+
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// ListRevisions returns the post's history oldest first, the last revision is what the post looks like now
+func (s *PostServiceRepository) ListRevisions(ctx context.Context, postID string) ([]*models.Revision, error) {
+	if s.Revisions == nil {
+		return nil, ErrNoRevisions
+	}
+	if _, err := s.Store.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return s.Revisions.ListByPost(ctx, postID)
+}
+
+// RevisionDiff is what changed between two revisions of the same post
+type RevisionDiff struct {
+	From    *models.Revision
+	To      *models.Revision
+	Title   []diff.Line
+	Content []diff.Line
+}
+
+// DiffRevisions compares two revisions line by line, from may be newer than to to see a change undone.
+// Revisions too different to compare are diff.ErrTooLarge.
+func (s *PostServiceRepository) DiffRevisions(ctx context.Context, postID string, from, to int) (*RevisionDiff, error) {
+	if s.Revisions == nil {
+		return nil, ErrNoRevisions
+	}
+	if _, err := s.Store.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	fromRev, err := s.Revisions.GetByNumber(ctx, postID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.Revisions.GetByNumber(ctx, postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &RevisionDiff{From: fromRev, To: toRev}
+	if d.Title, err = diff.Lines(fromRev.Name, toRev.Name); err != nil {
+		return nil, err
+	}
+	if d.Content, err = diff.Lines(fromRev.Content, toRev.Content); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// RestoreRevision puts an old title and content back. It is an ordinary edit: the same business rules
+// apply, e.g. the old title may have been taken by another post since, and it is recorded as a new revision.
+func (s *PostServiceRepository) RestoreRevision(ctx context.Context, postID string, number int, editorID string) (*models.Post, error) {
+	if s.Revisions == nil {
+		return nil, ErrNoRevisions
+	}
+	if _, err := s.Store.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	rev, err := s.Revisions.GetByNumber(ctx, postID, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdatePost(ctx, postID, editorID, &rev.Name, &rev.Content)
+}
+
+// renderHTML fills in ContentHTML, the renderer caches so this is cheap for content it has seen before
+func (s *PostServiceRepository) renderHTML(posts ...*models.Post) error {
+	if s.Renderer == nil {
+		return nil
+	}
 	for _, post := range posts {
-		if strings.EqualFold(post.Name, title) {
-			return true
+		html, err := s.Renderer.Render(post.Content)
+		if err != nil {
+			return err
+		}
+		post.ContentHTML = html
+	}
+	return nil
+}
+
+// PublishPost makes a post public at, or right away when at is zero. A future at schedules it.
+func (s *PostServiceRepository) PublishPost(ctx context.Context, id string, at time.Time) (*models.Post, error) {
+	now := s.clock()
+	if at.IsZero() {
+		at = now
+	}
+	return s.changeStatus(ctx, id, func(post *models.Post) { post.Publish(at, now) })
+}
+
+// UnpublishPost takes a published or scheduled post back to a draft
+func (s *PostServiceRepository) UnpublishPost(ctx context.Context, id string) (*models.Post, error) {
+	now := s.clock()
+	return s.changeStatus(ctx, id, func(post *models.Post) { post.Unpublish(now) })
+}
+
+// ArchivePost hides a post from readers without deleting it
+func (s *PostServiceRepository) ArchivePost(ctx context.Context, id string) (*models.Post, error) {
+	now := s.clock()
+	return s.changeStatus(ctx, id, func(post *models.Post) { post.Archive(now) })
+}
+
+func (s *PostServiceRepository) changeStatus(ctx context.Context, id string, change func(*models.Post)) (*models.Post, error) {
+	post, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	change(post)
+	if err := s.Store.Update(ctx, post); err != nil {
+		return nil, err
+	}
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// PublishDue publishes every scheduled post whose PublishAt has passed and reports how many it published
+func (s *PostServiceRepository) PublishDue(ctx context.Context) (int, error) {
+	now := s.clock()
+	published := 0
+	for {
+		// published posts drop out of the query, so the first page is always the next batch
+		due, err := s.Store.List(ctx, models.PostQuery{
+			Limit:        models.MaxPageSize,
+			SortBy:       models.SortByCreatedAt,
+			Status:       models.StatusScheduled,
+			PublishDueBy: now,
+		})
+		if err != nil {
+			return published, err
+		}
+		if len(due) == 0 {
+			return published, nil
+		}
+
+		for _, post := range due {
+			// keep the scheduled time, that is when the post was meant to go out
+			post.Publish(post.PublishAt, now)
+			if err := s.Store.Update(ctx, post); err != nil {
+				return published, err
+			}
+			published++
+		}
+	}
+}
+
+// RunScheduler calls PublishDue every interval until ctx is done, it is meant to run in its own goroutine
+func (s *PostServiceRepository) RunScheduler(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func() {
+		if n, err := s.PublishDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "scheduler failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "scheduler published posts", "count", n)
+		}
+	})
+}
+
+// RunPurger calls PurgeTrash every interval until ctx is done, it is meant to run in its own goroutine
+func (s *PostServiceRepository) RunPurger(ctx context.Context, interval, retention time.Duration) {
+	runEvery(ctx, interval, func() {
+		if n, err := s.PurgeTrash(ctx, retention); err != nil {
+			slog.ErrorContext(ctx, "purger failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "purger purged posts", "count", n)
+		}
+	})
+}
+
+// runEvery runs job right away and then on every tick until ctx is done
+func runEvery(ctx context.Context, interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyStatus validates the status a new post asked for and fills in PublishAt to match
+func applyStatus(post *models.Post, now time.Time) error {
+	switch post.Status {
+	case "", models.StatusPublished:
+		at := post.PublishAt
+		if at.IsZero() {
+			at = now
+		}
+		post.Publish(at, now)
+	case models.StatusScheduled:
+		if !post.PublishAt.After(now) {
+			return ErrMissingPublishAt
+		}
+		post.Publish(post.PublishAt, now)
+	case models.StatusDraft:
+		post.Unpublish(now)
+	case models.StatusArchived:
+		post.Archive(now)
+	default:
+		return models.ErrInvalidStatus
+	}
+	return nil
+}
+
+// ListTags returns every tag with its post count, most used first
+func (s *PostServiceRepository) ListTags(ctx context.Context) ([]models.TagCount, error) {
+	return s.Store.TagCounts(ctx)
+}
+
+// normalizeTags normalizes every tag, drops empty ones and duplicates and sorts the rest
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxTags {
+		return nil, ErrTooManyTags
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// NormalizeFilter lowercases a tag or category and turns runs of whitespace into a single dash,
+// "Web  Dev" becomes "web-dev". Every ?tag= and ?category= filter goes through it so it matches what was stored.
+func NormalizeFilter(v string) string {
+	return strings.ToLower(strings.Join(strings.Fields(v), "-"))
+}
+
+// normalizeTag is NormalizeFilter plus the rules a stored tag has to follow
+func normalizeTag(tag string) (string, error) {
+	tag = NormalizeFilter(tag)
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+			return "", ErrInvalidTag
+		}
+	}
+	return tag, nil
+}
+
+// uniqueSlug returns base, or base with the first free "-2", "-3"... suffix.
+// A slug the post itself has or had is free for it.
+func (s *PostServiceRepository) uniqueSlug(ctx context.Context, base, postID string) (string, error) {
+	if base == "" {
+		base = "post" // titles made only of symbols
+	}
+
+	slug := base
+	for n := 2; ; n++ {
+		owner, err := s.Store.GetBySlug(ctx, slug)
+		if errors.Is(err, store.ErrNotFound) {
+			return slug, nil
 		}
+		if err != nil {
+			return "", err
+		}
+		if owner.ID == postID {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(n)
+	}
+}
+
+// DeletePost moves a single post to the trash, store.ErrNotFound is passed through untouched.
+// Its comments and revisions stay until the post is purged, so a restore brings everything back.
+func (s *PostServiceRepository) DeletePost(ctx context.Context, id string) error {
+	if err := s.Store.Trash(ctx, id, s.clock().UTC()); err != nil {
+		return err
 	}
 
-	return false
+	if s.index != nil {
+		s.index.Remove(id)
+	}
+	slog.InfoContext(ctx, "post trashed", "post_id", id)
+	return nil
 }
 
-// wrapper delete servic
-func (s *PostServiceRepository) DeleteAll() error {
-	s.Store.DeleteAll()
+// wrapper delete servic, every post goes to the trash
+func (s *PostServiceRepository) DeleteAll(ctx context.Context) error {
+	if err := s.Store.TrashAll(ctx, s.clock().UTC()); err != nil {
+		return err
+	}
+
+	if s.index != nil {
+		s.index.Reset()
+	}
+	slog.InfoContext(ctx, "all posts trashed")
 	return nil
 }
+
+// ListTrash returns one page of trashed posts, most recently deleted first.
+// Like ListPosts it asks the store for one extra post to know whether there is a next page.
+func (s *PostServiceRepository) ListTrash(ctx context.Context, q models.TrashQuery) (*models.PostPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	q.Limit++
+	posts, err := s.Store.ListTrash(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PostPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		page.HasMore = true
+	}
+	if err := s.renderHTML(page.Posts...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetTrashedPost finds a post in the trash, store.ErrNotFound for live posts
+func (s *PostServiceRepository) GetTrashedPost(ctx context.Context, id string) (*models.Post, error) {
+	post, err := s.Store.GetTrashed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// RestorePost takes a post out of the trash with its comments and revisions.
+// Another post may have taken its title in the meantime, that is a ErrDuplicateTitle like on create.
+func (s *PostServiceRepository) RestorePost(ctx context.Context, id string) (*models.Post, error) {
+	trashed, err := s.GetTrashedPost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := s.titleTaken(ctx, trashed.Name, id, trashed.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrDuplicateTitle
+	}
+
+	if err := s.Store.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	post, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.index != nil {
+		s.index.Add(post)
+	}
+	slog.InfoContext(ctx, "post restored", "post_id", id)
+
+	if err := s.renderHTML(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// PurgeTrash deletes posts that have been in the trash longer than retention for good,
+// together with their comments and revisions, and reports how many it purged
+func (s *PostServiceRepository) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	purged, err := s.Store.Purge(ctx, s.clock().UTC().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	// the SQL stores cascade, the in-memory ones need to be told
+	for _, id := range purged {
+		if s.Comments != nil {
+			if err := s.Comments.DeleteByPost(ctx, id); err != nil {
+				return len(purged), err
+			}
+		}
+		if s.Revisions != nil {
+			if err := s.Revisions.DeleteByPost(ctx, id); err != nil {
+				return len(purged), err
+			}
+		}
+	}
+	return len(purged), nil
+}
+
+// SearchResult is a post together with how well it matched the query
+type SearchResult struct {
+	Post  *models.Post
+	Score float64
+}
+
+// maxSearchFetch bounds how many hits SearchPosts asks the searcher for while filling a page
+// with posts the caller may see
+const maxSearchFetch = 10 * models.MaxPageSize
+
+// SearchPosts runs a full-text query over titles and content, best matches first.
+// visible, when set, drops posts the caller may not see before the limit is applied,
+// so hidden drafts don't take up the page.
+func (s *PostServiceRepository) SearchPosts(ctx context.Context, query string, limit int, visible func(*models.Post) bool) ([]SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrEmptyQuery
+	}
+	if limit == 0 {
+		limit = models.DefaultPageSize
+	}
+	if limit < 0 || limit > models.MaxPageSize {
+		return nil, models.ErrInvalidLimit
+	}
+
+	// ask for more hits until the page is full, the searcher runs out or maxSearchFetch is reached
+	for fetch := limit; ; fetch = min(4*fetch, maxSearchFetch) {
+		hits, err := s.Searcher.Search(query, fetch)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]SearchResult, 0, limit)
+		for _, hit := range hits {
+			post, err := s.Store.GetByID(ctx, hit.PostID)
+			if errors.Is(err, store.ErrNotFound) {
+				continue // deleted since it was indexed, an external searcher may lag behind
+			}
+			if err != nil {
+				return nil, err
+			}
+			if visible != nil && !visible(post) {
+				continue
+			}
+			results = append(results, SearchResult{Post: post, Score: hit.Score})
+			if len(results) == limit {
+				break
+			}
+		}
+
+		if len(results) == limit || len(hits) < fetch || fetch == maxSearchFetch {
+			for _, result := range results {
+				if err := s.renderHTML(result.Post); err != nil {
+					return nil, err
+				}
+			}
+			return results, nil
+		}
+	}
+}
@@ -2,11 +2,20 @@ package service
 
 import (
 	// "strings"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
-	// "github.com/aziz-shoko/goblog/models"
+	"github.com/aziz-shoko/goblog/internal/diff"
+	"github.com/aziz-shoko/goblog/internal/search"
 	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestPostService_CreatePost(t *testing.T) {
@@ -53,21 +62,21 @@ func TestPostService_CreatePost(t *testing.T) {
 
 			// For the dplicate-title case
 			if tc.name == "prevent duplicate titles" {
-				_, err := service.CreatePost(tc.title, "First post content here")
+				_, err := service.CreatePost(t.Context(), tc.title, "First post content here")
 				if err != nil {
 					t.Fatalf("setup failed: %v", err)
 				}
 			}
 
 			// Act
-			post, err := service.CreatePost(tc.title, tc.content)
+			post, err := service.CreatePost(t.Context(), tc.title, tc.content)
 
 			// Assert error
 			AssertError(t, err, tc.wantErr)
 
 			if tc.wantErr == nil {
 				AssertTest(t, post.Name, tc.wantTitle)
-				stored, err := mockStore.GetByID(post.ID)
+				stored, err := mockStore.GetByID(t.Context(), post.ID)
 				AssertError(t, err, nil)
 				AssertTest(t, stored.Name, tc.wantTitle)
 			}
@@ -82,11 +91,11 @@ func TestPostService_Get(t *testing.T) {
 		mockStore := store.NewInMemoryStore()
 		service := NewPostService(mockStore)
 
-		post, err := service.CreatePost("Get Test Title", "Test content for get")
+		post, err := service.CreatePost(t.Context(), "Get Test Title", "Test content for get")
 		AssertError(t, err, nil)
 
 		// test
-		_, err = service.GetPostByID(post.ID)
+		_, err = service.GetPostByID(t.Context(), post.ID)
 		if err == store.ErrNotFound {
 			t.Errorf("GetByID operation failed")
 		}
@@ -98,11 +107,11 @@ func TestPostService_Get(t *testing.T) {
 		service := NewPostService(mockStore)
 
 		for i := range 5 {
-			_, err := service.CreatePost("title"+strconv.Itoa(i), "content"+strconv.Itoa(i))
+			_, err := service.CreatePost(t.Context(), "title"+strconv.Itoa(i), "content"+strconv.Itoa(i))
 			AssertError(t, err, nil)
 		}
 
-		posts, _ := service.ListAllPosts()
+		posts, _ := service.ListAllPosts(t.Context())
 		if len(posts) != 5 {
 			t.Errorf("Expected 5 posts but got %d posts", len(posts))
 		}
@@ -122,7 +131,8 @@ func AssertError(t testing.TB, got, want error) {
 		t.Fatalf("Expected no error but got %v", got)
 	}
 
-	if got != want {
+	// errors.Is so rules can wrap their sentinel with the details
+	if !errors.Is(got, want) {
 		t.Errorf("Got error %v want error %v", got, want)
 	}
 }
@@ -133,14 +143,847 @@ func TestPostService_DeleteAll(t *testing.T) {
 	service := NewPostService(mockStore)
 
 	for i := range 5 {
-		_, err := service.CreatePost("title"+strconv.Itoa(i), "content"+strconv.Itoa(i))
+		_, err := service.CreatePost(t.Context(), "title"+strconv.Itoa(i), "content"+strconv.Itoa(i))
 		AssertError(t, err, nil)
 	}
 
-	service.DeleteAll()
+	service.DeleteAll(t.Context())
 
-	_, err := service.ListAllPosts()
+	_, err := service.ListAllPosts(t.Context())
 	if err == nil {
 		t.Errorf("Expected error for empty posts but got nil")
 	}
 }
+
+func TestPostService_UpdatePost(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		title       *string
+		content     *string
+		wantErr     error
+		wantTitle   string
+		wantContent string
+	}{
+		{
+			name:        "replace title and content",
+			title:       strPtr("  Edited title  "),
+			content:     strPtr("Edited content"),
+			wantTitle:   "Edited title",
+			wantContent: "Edited content",
+		},
+		{
+			name:        "partial update keeps content",
+			title:       strPtr("Only the title"),
+			wantTitle:   "Only the title",
+			wantContent: "Original content",
+		},
+		{
+			name:        "partial update keeps title",
+			content:     strPtr("Only the content"),
+			wantTitle:   "Original title",
+			wantContent: "Only the content",
+		},
+		{
+			name:        "keeping its own title is not a duplicate",
+			title:       strPtr("ORIGINAL TITLE"),
+			wantTitle:   "ORIGINAL TITLE",
+			wantContent: "Original content",
+		},
+		{
+			name:    "reject content that is too short",
+			content: strPtr("Hi"),
+			wantErr: ErrContentTooShort,
+		},
+		{
+			name:    "reject another post's title",
+			title:   strPtr("other title"),
+			wantErr: ErrDuplicateTitle,
+		},
+		{
+			name:    "reject empty title",
+			title:   strPtr("   "),
+			wantErr: models.ErrEmtpyTitle,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStore := store.NewInMemoryStore()
+			service := NewPostService(mockStore)
+
+			post, err := service.CreatePost(t.Context(), "Original title", "Original content")
+			AssertError(t, err, nil)
+			_, err = service.CreatePost(t.Context(), "Other title", "Other content")
+			AssertError(t, err, nil)
+
+			updated, err := service.UpdatePost(t.Context(), post.ID, "", tc.title, tc.content)
+			AssertError(t, err, tc.wantErr)
+
+			stored, _ := mockStore.GetByID(t.Context(), post.ID)
+			if tc.wantErr != nil {
+				// failed updates must not touch the stored post
+				AssertTest(t, stored.Name, "Original title")
+				AssertTest(t, stored.Content, "Original content")
+				return
+			}
+
+			AssertTest(t, updated.Name, tc.wantTitle)
+			AssertTest(t, updated.Content, tc.wantContent)
+			AssertTest(t, stored.Name, tc.wantTitle)
+			AssertTest(t, stored.Content, tc.wantContent)
+		})
+	}
+
+	t.Run("unknown id", func(t *testing.T) {
+		service := NewPostService(store.NewInMemoryStore())
+		_, err := service.UpdatePost(t.Context(), "nope", "", nil, nil)
+		AssertError(t, err, store.ErrNotFound)
+	})
+}
+
+func TestPostService_DeletePost(t *testing.T) {
+	mockStore := store.NewInMemoryStore()
+	service := NewPostService(mockStore)
+
+	post, err := service.CreatePost(t.Context(), "Delete me", "Content to delete")
+	AssertError(t, err, nil)
+
+	AssertError(t, service.DeletePost(t.Context(), post.ID), nil)
+
+	_, err = service.GetPostByID(t.Context(), post.ID)
+	AssertError(t, err, store.ErrNotFound)
+
+	AssertError(t, service.DeletePost(t.Context(), post.ID), store.ErrNotFound)
+}
+
+func TestPostService_ListPosts(t *testing.T) {
+	mockStore := store.NewInMemoryStore()
+	service := NewPostService(mockStore)
+
+	for i := range 5 {
+		_, err := service.CreatePost(t.Context(), "title"+strconv.Itoa(i), "content"+strconv.Itoa(i))
+		AssertError(t, err, nil)
+	}
+
+	t.Run("pages report whether more are left", func(t *testing.T) {
+		q := models.PostQuery{Limit: 2, SortBy: models.SortByName}
+		seen := []string{}
+		for {
+			page, err := service.ListPosts(t.Context(), q)
+			AssertError(t, err, nil)
+			for _, p := range page.Posts {
+				seen = append(seen, p.Name)
+			}
+			if !page.HasMore {
+				break
+			}
+			q.Offset += q.Limit
+		}
+
+		if len(seen) != 5 {
+			t.Fatalf("Expected 5 posts across all pages, got %v", seen)
+		}
+		for i, name := range seen {
+			AssertTest(t, name, "title"+strconv.Itoa(i))
+		}
+	})
+
+	t.Run("exact fit has no next page", func(t *testing.T) {
+		page, err := service.ListPosts(t.Context(), models.PostQuery{Limit: 5})
+		AssertError(t, err, nil)
+		if len(page.Posts) != 5 || page.HasMore {
+			t.Errorf("Expected 5 posts and no next page, got %d posts, HasMore=%v", len(page.Posts), page.HasMore)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := service.ListPosts(t.Context(), models.PostQuery{SortBy: "nope"})
+		AssertError(t, err, models.ErrInvalidSort)
+	})
+}
+
+func TestPostService_SearchPosts(t *testing.T) {
+	mockStore := store.NewInMemoryStore()
+
+	// posts that exist before the service starts must be searchable too
+	existing, _ := models.NewPost("Existing gopher post", "Written before the service started")
+	mockStore.Create(t.Context(), existing)
+
+	service := NewPostService(mockStore)
+
+	gophers, err := service.CreatePost(t.Context(), "Gopher gopher gopher", "A post about the Go gopher")
+	AssertError(t, err, nil)
+	rust, err := service.CreatePost(t.Context(), "Crabs", "A post about the Rust mascot")
+	AssertError(t, err, nil)
+
+	searchIDs := func(query string) []string {
+		t.Helper()
+		results, err := service.SearchPosts(t.Context(), query, 0, nil)
+		AssertError(t, err, nil)
+		ids := []string{}
+		for _, r := range results {
+			ids = append(ids, r.Post.ID)
+		}
+		return ids
+	}
+
+	t.Run("finds created and pre-existing posts", func(t *testing.T) {
+		ids := searchIDs("gopher")
+		if len(ids) != 2 || ids[0] != gophers.ID {
+			t.Errorf("Expected %s first out of 2 hits, got %v", gophers.ID, ids)
+		}
+	})
+
+	t.Run("updates are reindexed", func(t *testing.T) {
+		newContent := "Now this post is about ferris the crab"
+		_, err := service.UpdatePost(t.Context(), rust.ID, "", nil, &newContent)
+		AssertError(t, err, nil)
+
+		if ids := searchIDs("mascot"); len(ids) != 0 {
+			t.Errorf("old content still matches: %v", ids)
+		}
+		if ids := searchIDs("ferris"); len(ids) != 1 {
+			t.Errorf("new content not found: %v", ids)
+		}
+	})
+
+	t.Run("deleted posts disappear", func(t *testing.T) {
+		AssertError(t, service.DeletePost(t.Context(), gophers.ID), nil)
+		if ids := searchIDs("gopher"); len(ids) != 1 || ids[0] != existing.ID {
+			t.Errorf("Expected only %s, got %v", existing.ID, ids)
+		}
+
+		AssertError(t, service.DeleteAll(t.Context()), nil)
+		if ids := searchIDs("gopher"); len(ids) != 0 {
+			t.Errorf("Expected no hits after DeleteAll, got %v", ids)
+		}
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, err := service.SearchPosts(t.Context(), "   ", 0, nil)
+		AssertError(t, err, ErrEmptyQuery)
+	})
+}
+
+// fakeSearcher stands in for a database backed full-text search
+type fakeSearcher struct {
+	hits []search.Hit
+}
+
+func (f *fakeSearcher) Search(query string, limit int) ([]search.Hit, error) {
+	return f.hits, nil
+}
+
+func TestPostService_WithSearcher(t *testing.T) {
+	mockStore := store.NewInMemoryStore()
+	post, _ := models.NewPost("External", "Found by the external searcher")
+	mockStore.Create(t.Context(), post)
+
+	external := &fakeSearcher{hits: []search.Hit{{PostID: "gone"}, {PostID: post.ID, Score: 1}}}
+	service := NewPostService(mockStore, WithSearcher(external))
+
+	results, err := service.SearchPosts(t.Context(), "anything", 0, nil)
+	AssertError(t, err, nil)
+
+	// hits for posts that no longer exist are skipped
+	if len(results) != 1 || results[0].Post.ID != post.ID {
+		t.Errorf("Expected only %s, got %+v", post.ID, results)
+	}
+}
+
+// searchingStore is a store with its own full-text search, like the SQL stores
+type searchingStore struct {
+	*store.InMemoryStore
+	fakeSearcher
+}
+
+func TestPostService_InstrumentedSearcherStore(t *testing.T) {
+	mockStore := &searchingStore{InMemoryStore: store.NewInMemoryStore()}
+	post, _ := models.NewPost("Indexed", "Found by the store's own search")
+	mockStore.Create(t.Context(), post)
+	mockStore.hits = []search.Hit{{PostID: post.ID, Score: 1}}
+
+	// the metrics wrapper must not hide the store's search behind the in-memory index
+	service := NewPostService(NewInstrumentedPostStore(mockStore, prometheus.NewRegistry()))
+	if service.index != nil {
+		t.Fatal("Expected the store's search to be used, got the in-memory index")
+	}
+
+	results, err := service.SearchPosts(t.Context(), "anything", 0, nil)
+	AssertError(t, err, nil)
+	if len(results) != 1 || results[0].Post.ID != post.ID {
+		t.Errorf("Expected %s from the store's search, got %+v", post.ID, results)
+	}
+}
+
+func TestPostService_SearchPosts_Visible(t *testing.T) {
+	service := NewPostService(store.NewInMemoryStore())
+	public, err := service.CreatePost(t.Context(), "Public bread", "Flour and water")
+	AssertError(t, err, nil)
+	// more drafts than the limit, each ranking above the public post
+	for i := range 5 {
+		_, err := service.CreatePost(t.Context(), "Bread draft "+strconv.Itoa(i), "bread bread bread", WithStatus(models.StatusDraft))
+		AssertError(t, err, nil)
+	}
+
+	visible := func(p *models.Post) bool { return p.IsPublic() }
+	results, err := service.SearchPosts(t.Context(), "bread", 2, visible)
+	AssertError(t, err, nil)
+	if len(results) != 1 || results[0].Post.ID != public.ID || results[0].Post.ContentHTML == "" {
+		t.Errorf("Expected only the public post, got %+v", results)
+	}
+}
+
+func TestPostService_CreatePost_Tags(t *testing.T) {
+	tests := []struct {
+		name         string
+		tags         []string
+		category     string
+		wantErr      error
+		wantTags     []string
+		wantCategory string
+	}{
+		{
+			name:         "tags are normalized, deduplicated and sorted",
+			tags:         []string{" Go ", "web  dev", "go", "", "Databases"},
+			category:     "  Tutorials ",
+			wantTags:     []string{"databases", "go", "web-dev"},
+			wantCategory: "tutorials",
+		},
+		{
+			name:     "no tags",
+			wantTags: []string{},
+		},
+		{
+			name:    "reject punctuation in tags",
+			tags:    []string{"c++"},
+			wantErr: ErrInvalidTag,
+		},
+		{
+			name:    "reject too many tags",
+			tags:    []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"},
+			wantErr: ErrTooManyTags,
+		},
+		{
+			name:     "reject invalid category",
+			category: "news/today",
+			wantErr:  ErrInvalidCategory,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewPostService(store.NewInMemoryStore())
+
+			post, err := service.CreatePost(t.Context(), "Tagged post", "Some tagged content", WithTags(tc.tags...), WithCategory(tc.category))
+			AssertError(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+
+			if !slices.Equal(post.Tags, tc.wantTags) {
+				t.Errorf("Got tags %q, want %q", post.Tags, tc.wantTags)
+			}
+			AssertTest(t, post.Category, tc.wantCategory)
+		})
+	}
+}
+
+func TestPostService_CreatePost_OptionsRunOnce(t *testing.T) {
+	service := NewPostService(store.NewInMemoryStore())
+
+	calls := 0
+	counted := func(p *models.Post) { calls++ }
+	post, err := service.CreatePost(t.Context(), "Once", "Some content", WithAuthor("alice"), counted)
+	AssertError(t, err, nil)
+	if calls != 1 {
+		t.Errorf("Expected the option to run once, ran %d times", calls)
+	}
+	AssertTest(t, post.AuthorID, "alice")
+	AssertTest(t, post.Slug, "once")
+}
+
+func TestPostService_ListTags(t *testing.T) {
+	service := NewPostService(store.NewInMemoryStore())
+
+	service.CreatePost(t.Context(), "First", "First content", WithTags("go", "sql"))
+	service.CreatePost(t.Context(), "Second", "Second content", WithTags("Go"))
+
+	tags, err := service.ListTags(t.Context())
+	AssertError(t, err, nil)
+
+	want := []models.TagCount{{Tag: "go", Count: 2}, {Tag: "sql", Count: 1}}
+	if !slices.Equal(tags, want) {
+		t.Errorf("Got %v, want %v", tags, want)
+	}
+
+	page, err := service.ListPosts(t.Context(), models.PostQuery{Tag: "go"})
+	AssertError(t, err, nil)
+	if len(page.Posts) != 2 {
+		t.Errorf("Expected 2 posts tagged go, got %d", len(page.Posts))
+	}
+}
+
+// countingRenderer records how often the service asks for a render
+type countingRenderer struct {
+	calls int
+}
+
+func (c *countingRenderer) Render(content string) (string, error) {
+	c.calls++
+	return "<p>" + content + "</p>", nil
+}
+
+func TestPostService_ContentHTML(t *testing.T) {
+	t.Run("markdown is rendered and sanitized", func(t *testing.T) {
+		service := NewPostService(store.NewInMemoryStore())
+
+		post, err := service.CreatePost(t.Context(), "Markdown", "Some **bold** text <script>alert(1)</script>")
+		AssertError(t, err, nil)
+		AssertTest(t, post.ContentHTML, "<p>Some <strong>bold</strong> text </p>\n")
+
+		updated := "# Heading"
+		post, err = service.UpdatePost(t.Context(), post.ID, "", nil, &updated)
+		AssertError(t, err, nil)
+		AssertTest(t, post.ContentHTML, "<h1>Heading</h1>\n")
+
+		got, err := service.GetPostByID(t.Context(), post.ID)
+		AssertError(t, err, nil)
+		AssertTest(t, got.ContentHTML, "<h1>Heading</h1>\n")
+	})
+
+	t.Run("every read path renders", func(t *testing.T) {
+		renderer := &countingRenderer{}
+		service := NewPostService(store.NewInMemoryStore(), WithRenderer(renderer))
+
+		post, _ := service.CreatePost(t.Context(), "Custom", "Custom renderer")
+		service.GetPostByID(t.Context(), post.ID)
+		service.ListAllPosts(t.Context())
+		page, _ := service.ListPosts(t.Context(), models.PostQuery{})
+		results, _ := service.SearchPosts(t.Context(), "custom", 0, nil)
+
+		if renderer.calls != 5 {
+			t.Errorf("Expected 5 renders, got %d", renderer.calls)
+		}
+		AssertTest(t, page.Posts[0].ContentHTML, "<p>Custom renderer</p>")
+		AssertTest(t, results[0].Post.ContentHTML, "<p>Custom renderer</p>")
+	})
+}
+
+func TestPostService_Slugs(t *testing.T) {
+	service := NewPostService(store.NewInMemoryStore())
+
+	first, err := service.CreatePost(t.Context(), "Café au lait", "First content")
+	AssertError(t, err, nil)
+	AssertTest(t, first.Slug, "cafe-au-lait")
+
+	// different titles, same slug
+	second, err := service.CreatePost(t.Context(), "Cafe au lait!", "Second content")
+	AssertError(t, err, nil)
+	AssertTest(t, second.Slug, "cafe-au-lait-2")
+
+	symbols, err := service.CreatePost(t.Context(), "???", "Symbols only")
+	AssertError(t, err, nil)
+	AssertTest(t, symbols.Slug, "post")
+
+	t.Run("content edits keep the slug", func(t *testing.T) {
+		content := "Edited content"
+		post, err := service.UpdatePost(t.Context(), second.ID, "", nil, &content)
+		AssertError(t, err, nil)
+		AssertTest(t, post.Slug, "cafe-au-lait-2")
+	})
+
+	t.Run("title edits move the slug and keep the old one", func(t *testing.T) {
+		title := "Flat white"
+		post, err := service.UpdatePost(t.Context(), first.ID, "", &title, nil)
+		AssertError(t, err, nil)
+		AssertTest(t, post.Slug, "flat-white")
+
+		old, err := service.GetPostBySlug(t.Context(), "cafe-au-lait")
+		AssertError(t, err, nil)
+		AssertTest(t, old.ID, first.ID)
+		AssertTest(t, old.Slug, "flat-white")
+	})
+
+	t.Run("old slugs are not handed out again", func(t *testing.T) {
+		post, err := service.CreatePost(t.Context(), "Café au lait", "Third content")
+		AssertError(t, err, nil)
+		AssertTest(t, post.Slug, "cafe-au-lait-3")
+	})
+
+	t.Run("unknown slug", func(t *testing.T) {
+		_, err := service.GetPostBySlug(t.Context(), "nope")
+		AssertError(t, err, store.ErrNotFound)
+	})
+}
+
+func TestPostService_Lifecycle(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		opts          []PostOption
+		wantErr       error
+		wantStatus    models.PostStatus
+		wantPublishAt time.Time
+	}{
+		{name: "published by default", wantStatus: models.StatusPublished, wantPublishAt: now},
+		{name: "draft", opts: []PostOption{WithStatus(models.StatusDraft)}, wantStatus: models.StatusDraft},
+		{name: "future publish_at schedules", opts: []PostOption{WithPublishAt(later)}, wantStatus: models.StatusScheduled, wantPublishAt: later},
+		{name: "explicitly scheduled", opts: []PostOption{WithStatus(models.StatusScheduled), WithPublishAt(later)}, wantStatus: models.StatusScheduled, wantPublishAt: later},
+		{name: "scheduled needs a time", opts: []PostOption{WithStatus(models.StatusScheduled)}, wantErr: ErrMissingPublishAt},
+		{name: "scheduled in the past", opts: []PostOption{WithStatus(models.StatusScheduled), WithPublishAt(now.Add(-time.Hour))}, wantErr: ErrMissingPublishAt},
+		{name: "unknown status", opts: []PostOption{WithStatus("secret")}, wantErr: models.ErrInvalidStatus},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewPostService(store.NewInMemoryStore(), WithClock(clock))
+
+			post, err := service.CreatePost(t.Context(), "Lifecycle", "Some content", tc.opts...)
+			AssertError(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+			if post.Status != tc.wantStatus || !post.PublishAt.Equal(tc.wantPublishAt) {
+				t.Errorf("Got %s at %v, want %s at %v", post.Status, post.PublishAt, tc.wantStatus, tc.wantPublishAt)
+			}
+		})
+	}
+
+	t.Run("publish, unpublish and archive", func(t *testing.T) {
+		service := NewPostService(store.NewInMemoryStore(), WithClock(clock))
+		post, _ := service.CreatePost(t.Context(), "Draft", "Some content", WithStatus(models.StatusDraft))
+
+		steps := []struct {
+			name   string
+			change func() (*models.Post, error)
+			want   models.PostStatus
+		}{
+			{name: "publish now", change: func() (*models.Post, error) { return service.PublishPost(t.Context(), post.ID, time.Time{}) }, want: models.StatusPublished},
+			{name: "unpublish", change: func() (*models.Post, error) { return service.UnpublishPost(t.Context(), post.ID) }, want: models.StatusDraft},
+			{name: "schedule", change: func() (*models.Post, error) { return service.PublishPost(t.Context(), post.ID, later) }, want: models.StatusScheduled},
+			{name: "archive", change: func() (*models.Post, error) { return service.ArchivePost(t.Context(), post.ID) }, want: models.StatusArchived},
+		}
+		for _, step := range steps {
+			changed, err := step.change()
+			AssertError(t, err, nil)
+			stored, _ := service.GetPostByID(t.Context(), post.ID)
+			if changed.Status != step.want || stored.Status != step.want {
+				t.Errorf("%s: got %s, stored %s, want %s", step.name, changed.Status, stored.Status, step.want)
+			}
+		}
+
+		_, err := service.PublishPost(t.Context(), "nope", time.Time{})
+		AssertError(t, err, store.ErrNotFound)
+	})
+}
+
+func TestPostService_PublishDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := NewPostService(store.NewInMemoryStore(), WithClock(func() time.Time { return now }))
+
+	// more than one batch of due posts
+	for i := range models.MaxPageSize + 5 {
+		_, err := service.CreatePost(t.Context(), "Due "+strconv.Itoa(i), "Some content", WithPublishAt(now.Add(time.Minute)))
+		AssertError(t, err, nil)
+	}
+	notDue, _ := service.CreatePost(t.Context(), "Not due", "Some content", WithPublishAt(now.Add(time.Hour)))
+	draft, _ := service.CreatePost(t.Context(), "Draft", "Some content", WithStatus(models.StatusDraft))
+
+	n, err := service.PublishDue(t.Context())
+	AssertError(t, err, nil)
+	if n != 0 {
+		t.Fatalf("Expected nothing due yet, published %d", n)
+	}
+
+	now = now.Add(30 * time.Minute)
+	n, err = service.PublishDue(t.Context())
+	AssertError(t, err, nil)
+	if n != models.MaxPageSize+5 {
+		t.Errorf("Expected %d published, got %d", models.MaxPageSize+5, n)
+	}
+
+	for _, id := range []string{notDue.ID, draft.ID} {
+		post, _ := service.GetPostByID(t.Context(), id)
+		if post.IsPublic() {
+			t.Errorf("%s should not have been published", post.Name)
+		}
+	}
+	page, _ := service.ListPosts(t.Context(), models.PostQuery{Limit: 1, Status: models.StatusPublished})
+	if !page.Posts[0].PublishAt.Equal(now.Add(-29 * time.Minute)) {
+		t.Errorf("Expected the scheduled time to be kept, got %v", page.Posts[0].PublishAt)
+	}
+}
+
+func TestPostService_RunScheduler(t *testing.T) {
+	now := time.Now()
+	service := NewPostService(store.NewInMemoryStore())
+	post, _ := service.CreatePost(t.Context(), "Soon", "Some content", WithPublishAt(now.Add(20*time.Millisecond)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.RunScheduler(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		stored, _ := service.GetPostByID(t.Context(), post.ID)
+		if stored.IsPublic() {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("scheduler never published the post")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	<-done
+}
+
+func TestPostService_Revisions(t *testing.T) {
+	service := NewPostService(store.NewInMemoryStore())
+
+	post, err := service.CreatePost(t.Context(), "Draft title", "one\ntwo\nthree", WithAuthor("alice"))
+	AssertError(t, err, nil)
+	content := "one\n2\nthree"
+	_, err = service.UpdatePost(t.Context(), post.ID, "bob", nil, &content)
+	AssertError(t, err, nil)
+	title := "Final title"
+	_, err = service.UpdatePost(t.Context(), post.ID, "alice", &title, nil)
+	AssertError(t, err, nil)
+
+	t.Run("every change is a revision", func(t *testing.T) {
+		// saving the same values again changes nothing
+		_, err := service.UpdatePost(t.Context(), post.ID, "carol", &title, &content)
+		AssertError(t, err, nil)
+
+		revisions, err := service.ListRevisions(t.Context(), post.ID)
+		AssertError(t, err, nil)
+		if len(revisions) != 3 {
+			t.Fatalf("Expected 3 revisions, got %d", len(revisions))
+		}
+		editors := []string{}
+		for i, rev := range revisions {
+			if rev.Number != i+1 {
+				t.Errorf("Expected revision %d, got %d", i+1, rev.Number)
+			}
+			editors = append(editors, rev.EditorID)
+		}
+		AssertTest(t, strings.Join(editors, ","), "alice,bob,alice")
+		AssertTest(t, revisions[0].Content, "one\ntwo\nthree")
+		AssertTest(t, revisions[2].Name, "Final title")
+	})
+
+	t.Run("edits are timed by the service clock", func(t *testing.T) {
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		clocked := NewPostService(store.NewInMemoryStore(), WithClock(func() time.Time { return now }))
+		post, err := clocked.CreatePost(t.Context(), "Clocked", "before")
+		AssertError(t, err, nil)
+
+		now = now.Add(time.Hour)
+		content := "after"
+		updated, err := clocked.UpdatePost(t.Context(), post.ID, "alice", nil, &content)
+		AssertError(t, err, nil)
+		if !updated.UpdatedAt.Equal(now) || !updated.CreatedAt.Equal(now.Add(-time.Hour)) {
+			t.Errorf("Expected UpdatedAt %v and CreatedAt an hour earlier, got %v and %v", now, updated.UpdatedAt, updated.CreatedAt)
+		}
+		revisions, _ := clocked.ListRevisions(t.Context(), post.ID)
+		if len(revisions) != 2 || !revisions[1].CreatedAt.Equal(now) {
+			t.Errorf("Expected the new revision at %v, got %+v", now, revisions)
+		}
+	})
+
+	t.Run("diff between revisions", func(t *testing.T) {
+		d, err := service.DiffRevisions(t.Context(), post.ID, 1, 3)
+		AssertError(t, err, nil)
+		AssertTest(t, diffString(d.Title), "-Draft title|+Final title")
+		AssertTest(t, diffString(d.Content), " one|-two|+2| three")
+
+		_, err = service.DiffRevisions(t.Context(), post.ID, 1, 9)
+		AssertError(t, err, store.ErrNotFound)
+	})
+
+	t.Run("restore is a new revision", func(t *testing.T) {
+		restored, err := service.RestoreRevision(t.Context(), post.ID, 1, "bob")
+		AssertError(t, err, nil)
+		AssertTest(t, restored.Name, "Draft title")
+		AssertTest(t, restored.Content, "one\ntwo\nthree")
+
+		revisions, _ := service.ListRevisions(t.Context(), post.ID)
+		latest := revisions[len(revisions)-1]
+		if latest.Number != 4 || latest.EditorID != "bob" || latest.Name != "Draft title" {
+			t.Errorf("unexpected latest revision %+v", latest)
+		}
+
+		_, err = service.RestoreRevision(t.Context(), post.ID, 99, "bob")
+		AssertError(t, err, store.ErrNotFound)
+	})
+
+	t.Run("restore goes through the business rules", func(t *testing.T) {
+		// someone else took the title the post used to have
+		other, err := service.CreatePost(t.Context(), "Final title", "Another post")
+		AssertError(t, err, nil)
+		_, err = service.RestoreRevision(t.Context(), post.ID, 3, "bob")
+		AssertError(t, err, ErrDuplicateTitle)
+		AssertError(t, service.DeletePost(t.Context(), other.ID), nil)
+	})
+
+	t.Run("unknown post and deleted history", func(t *testing.T) {
+		_, err := service.ListRevisions(t.Context(), "nope")
+		AssertError(t, err, store.ErrNotFound)
+
+		// a trashed post keeps its history until it is purged
+		AssertError(t, service.DeletePost(t.Context(), post.ID), nil)
+		if revisions, _ := service.Revisions.ListByPost(t.Context(), post.ID); len(revisions) != 4 {
+			t.Errorf("Expected the history to survive the trash, got %d revisions", len(revisions))
+		}
+	})
+}
+
+func TestPostService_RevisionsShareTheStore(t *testing.T) {
+	sqlite, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "blog.db"))
+	AssertError(t, err, nil)
+	t.Cleanup(func() { sqlite.Close() })
+
+	// the wrapper must not hide the database's revision store behind an in-memory one
+	service := NewPostService(NewInstrumentedPostStore(sqlite, prometheus.NewRegistry()))
+	post, err := service.CreatePost(t.Context(), "Stored", "Kept in sqlite", WithAuthor("alice"))
+	AssertError(t, err, nil)
+
+	revisions, err := sqlite.Revisions().ListByPost(t.Context(), post.ID)
+	AssertError(t, err, nil)
+	if len(revisions) != 1 || revisions[0].EditorID != "alice" {
+		t.Errorf("Expected the first revision in sqlite, got %+v", revisions)
+	}
+}
+
+// thirdPartyStore is a PostStore from outside internal/store, the service can't see its revisions
+type thirdPartyStore struct {
+	PostStore
+}
+
+func TestPostService_ThirdPartyStore(t *testing.T) {
+	inner := store.NewInMemoryStore()
+
+	t.Run("without a revision store the history is off", func(t *testing.T) {
+		service := NewPostService(thirdPartyStore{inner})
+		post, err := service.CreatePost(t.Context(), "Elsewhere", "Stored by someone else")
+		AssertError(t, err, nil)
+
+		_, err = service.ListRevisions(t.Context(), post.ID)
+		AssertError(t, err, ErrNoRevisions)
+		_, err = service.RestoreRevision(t.Context(), post.ID, 1, "")
+		AssertError(t, err, ErrNoRevisions)
+	})
+
+	t.Run("WithRevisionStore reads where the store writes", func(t *testing.T) {
+		service := NewPostService(thirdPartyStore{inner}, WithRevisionStore(inner.Revisions()))
+		post, err := service.CreatePost(t.Context(), "Tracked", "Stored by someone else", WithAuthor("alice"))
+		AssertError(t, err, nil)
+
+		revisions, err := service.ListRevisions(t.Context(), post.ID)
+		AssertError(t, err, nil)
+		if len(revisions) != 1 || revisions[0].EditorID != "alice" {
+			t.Errorf("Expected the first revision, got %+v", revisions)
+		}
+	})
+}
+
+// diffString joins a diff into "-old|+new| same" so a whole diff fits in one comparison
+func diffString(lines []diff.Line) string {
+	parts := []string{}
+	for _, line := range lines {
+		parts = append(parts, string(line.Op)+line.Text)
+	}
+	return strings.Join(parts, "|")
+}
+
+func TestPostService_Trash(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := NewPostService(store.NewInMemoryStore(), WithClock(func() time.Time { return now }))
+
+	post, err := service.CreatePost(t.Context(), "Binned", "Searchable content")
+	AssertError(t, err, nil)
+	AssertError(t, service.DeletePost(t.Context(), post.ID), nil)
+
+	t.Run("trashed posts are hidden", func(t *testing.T) {
+		_, err := service.GetPostByID(t.Context(), post.ID)
+		AssertError(t, err, store.ErrNotFound)
+		_, err = service.GetPostBySlug(t.Context(), "binned")
+		AssertError(t, err, store.ErrNotFound)
+		if results, _ := service.SearchPosts(t.Context(), "searchable", 0, nil); len(results) != 0 {
+			t.Errorf("Expected no search results for a trashed post, got %d", len(results))
+		}
+
+		trash, err := service.ListTrash(t.Context(), models.TrashQuery{})
+		AssertError(t, err, nil)
+		if len(trash.Posts) != 1 || trash.HasMore || !trash.Posts[0].DeletedAt.Equal(now) || trash.Posts[0].ContentHTML == "" {
+			t.Errorf("unexpected trash %+v", trash)
+		}
+		_, err = service.ListTrash(t.Context(), models.TrashQuery{Limit: models.MaxPageSize + 1})
+		AssertError(t, err, models.ErrInvalidLimit)
+
+		trashed, err := service.GetTrashedPost(t.Context(), post.ID)
+		AssertError(t, err, nil)
+		if trashed.Name != "Binned" || trashed.ContentHTML == "" {
+			t.Errorf("unexpected trashed post %+v", trashed)
+		}
+	})
+
+	t.Run("restore refuses a title taken in the meantime", func(t *testing.T) {
+		other, err := service.CreatePost(t.Context(), "Binned", "Took the title")
+		AssertError(t, err, nil)
+		// the trashed post still holds its slug
+		AssertTest(t, other.Slug, "binned-2")
+
+		_, err = service.RestorePost(t.Context(), post.ID)
+		AssertError(t, err, ErrDuplicateTitle)
+		AssertError(t, service.DeletePost(t.Context(), other.ID), nil)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		restored, err := service.RestorePost(t.Context(), post.ID)
+		AssertError(t, err, nil)
+		AssertTest(t, restored.Slug, "binned")
+		if results, _ := service.SearchPosts(t.Context(), "searchable", 0, nil); len(results) != 1 {
+			t.Errorf("Expected the restored post to be searchable again, got %d results", len(results))
+		}
+
+		_, err = service.RestorePost(t.Context(), post.ID)
+		AssertError(t, err, store.ErrNotFound)
+	})
+
+	t.Run("purge after the retention period", func(t *testing.T) {
+		AssertError(t, service.DeletePost(t.Context(), post.ID), nil)
+
+		// the post and the one from the previous subtest were trashed an hour ago
+		now = now.Add(time.Hour)
+		n, err := service.PurgeTrash(t.Context(), 2*time.Hour)
+		AssertError(t, err, nil)
+		if n != 0 {
+			t.Errorf("Expected nothing to be purged yet, got %d", n)
+		}
+
+		now = now.Add(2 * time.Hour)
+		n, err = service.PurgeTrash(t.Context(), 2*time.Hour)
+		AssertError(t, err, nil)
+		if n != 2 {
+			t.Errorf("Expected 2 purged posts, got %d", n)
+		}
+		if trash, _ := service.ListTrash(t.Context(), models.TrashQuery{}); len(trash.Posts) != 0 {
+			t.Errorf("Expected an empty trash, got %d", len(trash.Posts))
+		}
+		if revisions, _ := service.Revisions.ListByPost(t.Context(), post.ID); len(revisions) != 0 {
+			t.Errorf("Expected the history to go with the purged post, got %d revisions", len(revisions))
+		}
+	})
+}
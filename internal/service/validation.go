@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+var (
+	ErrTitleTooShort    = errors.New("title is too short")
+	ErrTitleTooLong     = errors.New("title is too long")
+	ErrContentTooLong   = errors.New("content is too long")
+	ErrBannedWord       = errors.New("contains a banned word")
+	ErrInvalidTitleChar = errors.New("title contains a character that is not allowed")
+)
+
+// DuplicateScope decides which posts a title has to be unique among
+type DuplicateScope string
+
+const (
+	// DuplicatesGlobal keeps every title unique across the blog, case insensitive
+	DuplicatesGlobal DuplicateScope = "global"
+	// DuplicatesPerAuthor only stops an author from reusing one of their own titles
+	DuplicatesPerAuthor DuplicateScope = "author"
+	// DuplicatesAllowed turns the check off, slugs still stay unique
+	DuplicatesAllowed DuplicateScope = "none"
+)
+
+// ValidationPolicy is the set of rules a post's title and content have to pass.
+// Lengths count characters, not bytes, and a zero maximum means no limit.
+type ValidationPolicy struct {
+	MinTitleLength   int
+	MaxTitleLength   int
+	MinContentLength int
+	MaxContentLength int
+
+	// BannedWords are rejected as whole words in the title or content, case insensitive.
+	// Text is split on anything but letters and digits, so a phrase never matches.
+	BannedWords []string
+
+	// TitleChars matches a single allowed title character, e.g. `[\p{L}\p{N} .,:!?'-]`. nil allows anything.
+	TitleChars *regexp.Regexp
+
+	DuplicateTitles DuplicateScope
+}
+
+// DefaultValidationPolicy is what NewPostService uses unless WithValidationPolicy says otherwise
+func DefaultValidationPolicy() ValidationPolicy {
+	return ValidationPolicy{
+		MinTitleLength:   1,
+		MinContentLength: DefaultMinContentLength,
+		DuplicateTitles:  DuplicatesGlobal,
+	}
+}
+
+// FieldError is one broken rule on one field of a post, Field is the name the API uses for it
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+
+func (e FieldError) Unwrap() error { return e.Err }
+
+// ValidationErrors is every rule a post broke, in field order.
+// errors.Is matches any of them, so callers can still check for a single sentinel.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
+}
+
+// validatePost runs the policy over a new or edited post. exceptID is the post being edited, if any,
+// authorID is only needed for DuplicatesPerAuthor. It returns nil, a ValidationErrors, or the store's
+// error when it could not look for duplicate titles.
+func (s *PostServiceRepository) validatePost(ctx context.Context, title, content, exceptID, authorID string) error {
+	p := s.policy
+	var errs ValidationErrors
+	add := func(field string, err error) {
+		errs = append(errs, FieldError{Field: field, Err: err})
+	}
+
+	titleLen := utf8.RuneCountInString(title)
+	switch {
+	case title == "":
+		add("name", models.ErrEmtpyTitle)
+	case titleLen < p.MinTitleLength:
+		add("name", fmt.Errorf("%w, must contain at least %d chars", ErrTitleTooShort, p.MinTitleLength))
+	case p.MaxTitleLength > 0 && titleLen > p.MaxTitleLength:
+		add("name", fmt.Errorf("%w, must contain at most %d chars", ErrTitleTooLong, p.MaxTitleLength))
+	}
+	if p.TitleChars != nil {
+		for _, r := range title {
+			if !p.TitleChars.MatchString(string(r)) {
+				add("name", fmt.Errorf("%w: %q", ErrInvalidTitleChar, r))
+				break
+			}
+		}
+	}
+	if word := bannedWord(title, p.BannedWords); word != "" {
+		add("name", fmt.Errorf("%w: %q", ErrBannedWord, word))
+	}
+	if title != "" {
+		taken, err := s.titleTaken(ctx, title, exceptID, authorID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			add("name", ErrDuplicateTitle)
+		}
+	}
+
+	contentLen := utf8.RuneCountInString(content)
+	switch {
+	case content == "":
+		add("content", models.ErrEmtpyContent)
+	case contentLen < p.MinContentLength:
+		add("content", fmt.Errorf("%w, must contain at least %d chars", ErrContentTooShort, p.MinContentLength))
+	case p.MaxContentLength > 0 && contentLen > p.MaxContentLength:
+		add("content", fmt.Errorf("%w, must contain at most %d chars", ErrContentTooLong, p.MaxContentLength))
+	}
+	if word := bannedWord(content, p.BannedWords); word != "" {
+		add("content", fmt.Errorf("%w: %q", ErrBannedWord, word))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// bannedWord returns the first word of text that is on the banned list, or ""
+func bannedWord(text string, banned []string) string {
+	if len(banned) == 0 {
+		return ""
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if slices.ContainsFunc(banned, func(b string) bool { return strings.EqualFold(b, word) }) {
+			return strings.ToLower(word)
+		}
+	}
+	return ""
+}
+
+// titleTaken reports whether a post other than exceptID already uses title, within the policy's scope
+func (s *PostServiceRepository) titleTaken(ctx context.Context, title, exceptID, authorID string) (bool, error) {
+	if s.policy.DuplicateTitles == DuplicatesAllowed {
+		return false, nil
+	}
+
+	posts, err := s.Store.FindByTitle(ctx, title)
+	if err != nil {
+		return false, err
+	}
+
+	for _, post := range posts {
+		if post.ID == exceptID {
+			continue
+		}
+		if s.policy.DuplicateTitles == DuplicatesPerAuthor && post.AuthorID != authorID {
+			continue
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func TestPostService_ValidationPolicy(t *testing.T) {
+	strict := ValidationPolicy{
+		MinTitleLength:   3,
+		MaxTitleLength:   20,
+		MinContentLength: 10,
+		MaxContentLength: 40,
+		BannedWords:      []string{"spam", "scam"},
+		TitleChars:       regexp.MustCompile(`[\p{L}\p{N} ]`),
+		DuplicateTitles:  DuplicatesGlobal,
+	}
+
+	tests := []struct {
+		name    string
+		policy  ValidationPolicy
+		title   string
+		content string
+		want    []FieldError
+	}{
+		{
+			name:    "a post within every rule",
+			policy:  strict,
+			title:   "Fine title",
+			content: "Long enough content",
+		},
+		{
+			name:    "lengths count characters not bytes",
+			policy:  strict,
+			title:   "Ünïcödé",
+			content: "ääääääääää",
+		},
+		{
+			name:    "every failure is reported at once",
+			policy:  strict,
+			title:   "No!",
+			content: "Tiny",
+			want: []FieldError{
+				{"name", ErrInvalidTitleChar},
+				{"content", ErrContentTooShort},
+			},
+		},
+		{
+			name:    "too long on both fields",
+			policy:  strict,
+			title:   "A title that goes on and on",
+			content: "Content that is far longer than forty characters allows",
+			want: []FieldError{
+				{"name", ErrTitleTooLong},
+				{"content", ErrContentTooLong},
+			},
+		},
+		{
+			name:    "banned words are whole words in any case",
+			policy:  strict,
+			title:   "Not SPAM at all",
+			content: "This is a scam, trust me",
+			want: []FieldError{
+				{"name", ErrBannedWord},
+				{"content", ErrBannedWord},
+			},
+		},
+		{
+			name:    "banned words inside other words are fine",
+			policy:  strict,
+			title:   "Spammer tales",
+			content: "Escamotage is a word",
+		},
+		{
+			name:    "title too short and duplicate",
+			policy:  ValidationPolicy{MinTitleLength: 6, DuplicateTitles: DuplicatesGlobal},
+			title:   "TAKEN",
+			content: "whatever",
+			want: []FieldError{
+				{"name", ErrTitleTooShort},
+				{"name", ErrDuplicateTitle},
+			},
+		},
+		{
+			name:    "empty title and content",
+			policy:  ValidationPolicy{},
+			title:   "  ",
+			content: "",
+			want: []FieldError{
+				{"name", models.ErrEmtpyTitle},
+				{"content", models.ErrEmtpyContent},
+			},
+		},
+		{
+			name:    "empty content is empty, not too short",
+			policy:  strict,
+			title:   "Fine title",
+			content: "",
+			want: []FieldError{
+				{"content", models.ErrEmtpyContent},
+			},
+		},
+		{
+			name:    "duplicates can be allowed",
+			policy:  ValidationPolicy{DuplicateTitles: DuplicatesAllowed},
+			title:   "Taken",
+			content: "Same title again",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStore := store.NewInMemoryStore()
+			existing, _ := models.NewPost("Taken", "Already published")
+			mockStore.Create(t.Context(), existing)
+			service := NewPostService(mockStore, WithValidationPolicy(tc.policy))
+
+			_, err := service.CreatePost(t.Context(), tc.title, tc.content)
+			if tc.want == nil {
+				AssertError(t, err, nil)
+				return
+			}
+
+			var got ValidationErrors
+			if !errors.As(err, &got) {
+				t.Fatalf("Expected ValidationErrors, got %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Expected %d failures, got %v", len(tc.want), got)
+			}
+			for i, want := range tc.want {
+				if got[i].Field != want.Field || !errors.Is(got[i], want.Err) {
+					t.Errorf("Failure %d: got %v, want %s: %v", i, got[i], want.Field, want.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestPostService_DuplicateTitlesPerAuthor(t *testing.T) {
+	service := NewPostService(store.NewInMemoryStore(), WithValidationPolicy(ValidationPolicy{DuplicateTitles: DuplicatesPerAuthor}))
+
+	alices, err := service.CreatePost(t.Context(), "Weekly notes", "Alice's notes", WithAuthor("alice"))
+	AssertError(t, err, nil)
+
+	// another author may use the same title
+	bobs, err := service.CreatePost(t.Context(), "Weekly notes", "Bob's notes", WithAuthor("bob"))
+	AssertError(t, err, nil)
+	if bobs.Slug == alices.Slug {
+		t.Errorf("Expected distinct slugs, both are %s", bobs.Slug)
+	}
+
+	// but not one of their own again
+	_, err = service.CreatePost(t.Context(), "weekly NOTES", "Alice again", WithAuthor("alice"))
+	AssertError(t, err, ErrDuplicateTitle)
+
+	// same rule when editing
+	other, err := service.CreatePost(t.Context(), "Other", "Bob's other post", WithAuthor("bob"))
+	AssertError(t, err, nil)
+	title := "Weekly Notes"
+	_, err = service.UpdatePost(t.Context(), other.ID, "bob", &title, nil)
+	AssertError(t, err, ErrDuplicateTitle)
+}
+
+func TestValidationErrors(t *testing.T) {
+	err := error(ValidationErrors{
+		{"name", ErrTitleTooShort},
+		{"content", ErrBannedWord},
+	})
+
+	if got, want := err.Error(), "name: title is too short; content: contains a banned word"; got != want {
+		t.Errorf("Got %q want %q", got, want)
+	}
+	for _, target := range []error{ErrTitleTooShort, ErrBannedWord} {
+		if !errors.Is(err, target) {
+			t.Errorf("Expected errors.Is to find %v", target)
+		}
+	}
+	if errors.Is(err, ErrDuplicateTitle) {
+		t.Error("Did not expect ErrDuplicateTitle")
+	}
+
+	var field FieldError
+	if !errors.As(err, &field) || field.Field != "name" {
+		t.Errorf("Expected the first FieldError, got %+v", field)
+	}
+}
+
+// brokenTitleStore can't look up titles the way a database that went away can't
+type brokenTitleStore struct {
+	*store.InMemoryStore
+}
+
+func (brokenTitleStore) FindByTitle(context.Context, string) ([]*models.Post, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestPostService_DuplicateCheckFails(t *testing.T) {
+	mockStore := brokenTitleStore{store.NewInMemoryStore()}
+	service := NewPostService(mockStore)
+
+	// the title can't be checked, that is an error and not a pass
+	_, err := service.CreatePost(t.Context(), "Unchecked", "Some content")
+	if err == nil || err.Error() != "connection refused" {
+		t.Fatalf("Expected the store error, got %v", err)
+	}
+
+	post, _ := models.NewPost("Binned", "Some content")
+	mockStore.Create(t.Context(), post)
+	mockStore.Trash(t.Context(), post.ID, time.Now())
+	if _, err := service.RestorePost(t.Context(), post.ID); err == nil || err.Error() != "connection refused" {
+		t.Errorf("Expected the store error on restore, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// InMemoryAuthorStore keeps authors in a map, locked the same way as InMemoryStore
+type InMemoryAuthorStore struct {
+	mu      sync.RWMutex
+	authors map[string]*models.Author
+}
+
+func NewInMemoryAuthorStore() *InMemoryAuthorStore {
+	return &InMemoryAuthorStore{
+		authors: make(map[string]*models.Author),
+	}
+}
+
+// Create adds an author, ErrAlreadyExists if the ID is taken
+func (s *InMemoryAuthorStore) Create(ctx context.Context, author *models.Author) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if author == nil {
+		return errors.New("author cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authors[author.ID]; ok {
+		return ErrAlreadyExists
+	}
+	cp := *author
+	s.authors[author.ID] = &cp
+	return nil
+}
+
+func (s *InMemoryAuthorStore) GetByID(ctx context.Context, id string) (*models.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	author, ok := s.authors[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *author
+	return &cp, nil
+}
+
+// List returns every author sorted by name, an empty store gives an empty list
+func (s *InMemoryAuthorStore) List(ctx context.Context) ([]*models.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	authors := make([]*models.Author, 0, len(s.authors))
+	for _, author := range s.authors {
+		cp := *author
+		authors = append(authors, &cp)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(authors, func(i, j int) bool {
+		a, b := strings.ToLower(authors[i].Name), strings.ToLower(authors[j].Name)
+		if a != b {
+			return a < b
+		}
+		return authors[i].ID < authors[j].ID
+	})
+	return authors, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type authorStore interface {
+	Create(context.Context, *models.Author) error
+	GetByID(context.Context, string) (*models.Author, error)
+	List(ctx context.Context) ([]*models.Author, error)
+}
+
+func runAuthorStoreTests(t *testing.T, newStore func(t *testing.T) authorStore) {
+	t.Run("create and get", func(t *testing.T) {
+		s := newStore(t)
+
+		if err := s.Create(t.Context(), nil); err == nil {
+			t.Fatal("expected error for nil author, got nil")
+		}
+
+		author, _ := models.NewAuthor("alice", "Alice", "Writes about Go")
+		if err := s.Create(t.Context(), author); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := s.GetByID(t.Context(), "alice")
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Alice" || got.Bio != "Writes about Go" || !got.CreatedAt.Equal(author.CreatedAt) {
+			t.Errorf("stored author mismatch: got %+v, want %+v", got, author)
+		}
+
+		if _, err := s.GetByID(t.Context(), "nobody"); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+
+		again, _ := models.NewAuthor("alice", "Other Alice", "")
+		if err := s.Create(t.Context(), again); err != ErrAlreadyExists {
+			t.Errorf("Got error %v wanted error %v", err, ErrAlreadyExists)
+		}
+	})
+
+	t.Run("list sorted by name", func(t *testing.T) {
+		s := newStore(t)
+
+		authors, err := s.List(t.Context())
+		if err != nil || len(authors) != 0 {
+			t.Fatalf("expected empty list, got %v %v", authors, err)
+		}
+
+		for _, name := range []string{"carol", "Bob", "alice"} {
+			author, _ := models.NewAuthor(name+"-id", name, "")
+			s.Create(t.Context(), author)
+		}
+
+		authors, _ = s.List(t.Context())
+		got := []string{}
+		for _, a := range authors {
+			got = append(got, a.Name)
+		}
+		if len(got) != 3 || got[0] != "alice" || got[1] != "Bob" || got[2] != "carol" {
+			t.Errorf("unexpected order %v", got)
+		}
+	})
+}
+
+func TestInMemoryAuthorStore(t *testing.T) {
+	runAuthorStoreTests(t, func(t *testing.T) authorStore { return NewInMemoryAuthorStore() })
+}
+
+func TestSQLiteAuthorStore(t *testing.T) {
+	runAuthorStoreTests(t, func(t *testing.T) authorStore { return newTestSQLiteStore(t).Authors() })
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// InMemoryCommentStore keeps comments in a map, locked the same way as InMemoryStore
+type InMemoryCommentStore struct {
+	mu       sync.RWMutex
+	comments map[string]*models.Comment
+}
+
+func NewInMemoryCommentStore() *InMemoryCommentStore {
+	return &InMemoryCommentStore{
+		comments: make(map[string]*models.Comment),
+	}
+}
+
+func (s *InMemoryCommentStore) Create(ctx context.Context, comment *models.Comment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if comment == nil {
+		return errors.New("comment cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := *comment
+	s.comments[comment.ID] = &cp
+	return nil
+}
+
+func (s *InMemoryCommentStore) GetByID(ctx context.Context, id string) (*models.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *comment
+	return &cp, nil
+}
+
+// ListByPost returns every comment on a post oldest first, replies included
+func (s *InMemoryCommentStore) ListByPost(ctx context.Context, postID string) ([]*models.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	comments := []*models.Comment{}
+	for _, comment := range s.comments {
+		if comment.PostID == postID {
+			cp := *comment
+			comments = append(comments, &cp)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+// DeleteByPost removes every comment on a post, a post without comments is not an error
+func (s *InMemoryCommentStore) DeleteByPost(ctx context.Context, postID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, comment := range s.comments {
+		if comment.PostID == postID {
+			delete(s.comments, id)
+		}
+	}
+	return nil
+}
+
+func (s *InMemoryCommentStore) DeleteAll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.comments = make(map[string]*models.Comment)
+	return nil
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type commentStore interface {
+	Create(context.Context, *models.Comment) error
+	GetByID(context.Context, string) (*models.Comment, error)
+	ListByPost(context.Context, string) ([]*models.Comment, error)
+	DeleteByPost(context.Context, string) error
+	DeleteAll(ctx context.Context) error
+}
+
+// runCommentStoreTests gets posts created through createPost because the SQL stores enforce the foreign key
+func runCommentStoreTests(t *testing.T, newStore func(t *testing.T) (commentStore, func(context.Context, *models.Post) error)) {
+	newComment := func(postID, parentID, body string, at time.Time) *models.Comment {
+		c, _ := models.NewComment(postID, parentID, "alice", body)
+		c.CreatedAt = at
+		return c
+	}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("create, get and list in order", func(t *testing.T) {
+		s, createPost := newStore(t)
+		post, _ := models.NewPost("Post", "Content")
+		other, _ := models.NewPost("Other", "Content")
+		createPost(t.Context(), post)
+		createPost(t.Context(), other)
+
+		if err := s.Create(t.Context(), nil); err == nil {
+			t.Fatal("expected error for nil comment, got nil")
+		}
+
+		second := newComment(post.ID, "", "second", base.Add(time.Minute))
+		first := newComment(post.ID, "", "first", base)
+		reply := newComment(post.ID, first.ID, "reply", base.Add(2*time.Minute))
+		elsewhere := newComment(other.ID, "", "elsewhere", base)
+		for _, c := range []*models.Comment{first, second, reply, elsewhere} {
+			if err := s.Create(t.Context(), c); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		got, err := s.GetByID(t.Context(), reply.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.ParentID != first.ID || got.Body != "reply" || got.AuthorID != "alice" || !got.CreatedAt.Equal(reply.CreatedAt) {
+			t.Errorf("stored comment mismatch: got %+v, want %+v", got, reply)
+		}
+		if top, _ := s.GetByID(t.Context(), first.ID); top.ParentID != "" {
+			t.Errorf("top level comment got parent %q", top.ParentID)
+		}
+		if _, err := s.GetByID(t.Context(), "nope"); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+
+		comments, err := s.ListByPost(t.Context(), post.ID)
+		if err != nil {
+			t.Fatalf("ListByPost: %v", err)
+		}
+		bodies := []string{}
+		for _, c := range comments {
+			bodies = append(bodies, c.Body)
+		}
+		if len(bodies) != 3 || bodies[0] != "first" || bodies[1] != "second" || bodies[2] != "reply" {
+			t.Errorf("unexpected comments %v", bodies)
+		}
+	})
+
+	t.Run("delete by post and delete all", func(t *testing.T) {
+		s, createPost := newStore(t)
+		post, _ := models.NewPost("Post", "Content")
+		other, _ := models.NewPost("Other", "Content")
+		createPost(t.Context(), post)
+		createPost(t.Context(), other)
+		s.Create(t.Context(), newComment(post.ID, "", "on post", base))
+		s.Create(t.Context(), newComment(other.ID, "", "on other", base))
+
+		if err := s.DeleteByPost(t.Context(), post.ID); err != nil {
+			t.Fatalf("DeleteByPost: %v", err)
+		}
+		if comments, _ := s.ListByPost(t.Context(), post.ID); len(comments) != 0 {
+			t.Errorf("expected no comments left, got %d", len(comments))
+		}
+		if comments, _ := s.ListByPost(t.Context(), other.ID); len(comments) != 1 {
+			t.Errorf("DeleteByPost removed comments on another post")
+		}
+
+		if err := s.DeleteAll(t.Context()); err != nil {
+			t.Fatalf("DeleteAll: %v", err)
+		}
+		if comments, _ := s.ListByPost(t.Context(), other.ID); len(comments) != 0 {
+			t.Errorf("expected no comments left, got %d", len(comments))
+		}
+	})
+}
+
+func TestInMemoryCommentStore(t *testing.T) {
+	runCommentStoreTests(t, func(t *testing.T) (commentStore, func(context.Context, *models.Post) error) {
+		return NewInMemoryCommentStore(), NewInMemoryStore().Create
+	})
+}
+
+func TestSQLiteCommentStore(t *testing.T) {
+	runCommentStoreTests(t, func(t *testing.T) (commentStore, func(context.Context, *models.Post) error) {
+		s := newTestSQLiteStore(t)
+		return s.Comments(), s.Create
+	})
+}
+
+func TestSQLiteCommentStore_CascadesOnPostDelete(t *testing.T) {
+	s := newTestSQLiteStore(t)
+	comments := s.Comments()
+
+	post, _ := models.NewPost("Post", "Content")
+	s.Create(t.Context(), post)
+	top, _ := models.NewComment(post.ID, "", "alice", "top")
+	comments.Create(t.Context(), top)
+
+	// the foreign key rejects comments on posts that don't exist
+	orphan, _ := models.NewComment("missing-post", "", "alice", "orphan")
+	if err := comments.Create(t.Context(), orphan); err == nil {
+		t.Error("expected foreign key error for a missing post")
+	}
+
+	if err := s.Delete(t.Context(), post.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := comments.GetByID(t.Context(), top.ID); err != ErrNotFound {
+		t.Errorf("expected comment to cascade away, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// postLister is the part of the store API the listing tests need,
+// so the same table runs against the in-memory store and every SQL backend
+type postLister interface {
+	Create(context.Context, *models.Post) error
+	List(context.Context, models.PostQuery) ([]*models.Post, error)
+	TagCounts(ctx context.Context) ([]models.TagCount, error)
+	FindByTitle(ctx context.Context, title string) ([]*models.Post, error)
+}
+
+func runListTests(t *testing.T, newStore func(t *testing.T) postLister) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// post0 is the oldest, names run the other way round so the two sorts disagree
+	names := []string{"echo", "Delta", "charlie", "Bravo", "alpha"}
+	tags := [][]string{{"go"}, {"go", "sql"}, nil, {"sql"}, {"go"}}
+	seed := func(t *testing.T) postLister {
+		s := newStore(t)
+		for i, name := range names {
+			post, _ := models.NewPost(name, "Content "+strconv.Itoa(i))
+			post.AuthorID = []string{"alice", "bob"}[i%2]
+			post.Tags = tags[i]
+			if i < 2 {
+				post.Category = "news"
+			}
+			post.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+			post.UpdatedAt = post.CreatedAt
+			switch name {
+			case "charlie":
+				post.Unpublish(post.CreatedAt)
+			case "Bravo":
+				post.Publish(base.Add(48*time.Hour), post.CreatedAt)
+			}
+			if err := s.Create(t.Context(), post); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		return s
+	}
+
+	cases := []struct {
+		name  string
+		query models.PostQuery
+		want  []string
+	}{
+		{
+			name:  "created_at ascending",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt},
+			want:  []string{"echo", "Delta", "charlie", "Bravo", "alpha"},
+		},
+		{
+			name:  "created_at descending",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Descending: true},
+			want:  []string{"alpha", "Bravo", "charlie", "Delta", "echo"},
+		},
+		{
+			name:  "name is case insensitive",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByName},
+			want:  []string{"alpha", "Bravo", "charlie", "Delta", "echo"},
+		},
+		{
+			name:  "first page",
+			query: models.PostQuery{Limit: 2, SortBy: models.SortByCreatedAt},
+			want:  []string{"echo", "Delta"},
+		},
+		{
+			name:  "second page",
+			query: models.PostQuery{Limit: 2, Offset: 2, SortBy: models.SortByCreatedAt},
+			want:  []string{"charlie", "Bravo"},
+		},
+		{
+			name:  "past the end",
+			query: models.PostQuery{Limit: 2, Offset: 10, SortBy: models.SortByCreatedAt},
+			want:  []string{},
+		},
+		{
+			name:  "by author",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, AuthorID: "bob"},
+			want:  []string{"Delta", "Bravo"},
+		},
+		{
+			name:  "by tag",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Tag: "go"},
+			want:  []string{"echo", "Delta", "alpha"},
+		},
+		{
+			name:  "by tag and author",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Tag: "sql", AuthorID: "bob"},
+			want:  []string{"Delta", "Bravo"},
+		},
+		{
+			name:  "by category",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Category: "news"},
+			want:  []string{"echo", "Delta"},
+		},
+		{
+			name:  "public only",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, PublicOnly: true},
+			want:  []string{"echo", "Delta", "alpha"},
+		},
+		{
+			name:  "public plus the viewer's own",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, PublicOnly: true, Viewer: "alice"},
+			want:  []string{"echo", "Delta", "charlie", "alpha"},
+		},
+		{
+			name:  "by status",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Status: models.StatusDraft},
+			want:  []string{"charlie"},
+		},
+		{
+			name:  "scheduled and due",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Status: models.StatusScheduled, PublishDueBy: base.Add(48 * time.Hour)},
+			want:  []string{"Bravo"},
+		},
+		{
+			name:  "scheduled but not due yet",
+			query: models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Status: models.StatusScheduled, PublishDueBy: base.Add(47 * time.Hour)},
+			want:  []string{},
+		},
+		{
+			name: "created window is exclusive",
+			query: models.PostQuery{
+				Limit:         10,
+				SortBy:        models.SortByCreatedAt,
+				CreatedAfter:  base,
+				CreatedBefore: base.Add(3 * time.Hour),
+			},
+			want: []string{"Delta", "charlie"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := seed(t)
+			posts, err := s.List(t.Context(), tc.query)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+
+			got := []string{}
+			for _, p := range posts {
+				got = append(got, p.Name)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Got %v wanted %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("tags come back with the post", func(t *testing.T) {
+		s := seed(t)
+		posts, err := s.List(t.Context(), models.PostQuery{Limit: 10, SortBy: models.SortByCreatedAt, Tag: "sql"})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(posts) != 2 || !slices.Equal(posts[0].Tags, []string{"go", "sql"}) || posts[0].Category != "news" {
+			t.Errorf("Got %+v", posts)
+		}
+	})
+
+	t.Run("status and publish time come back with the post", func(t *testing.T) {
+		s := seed(t)
+		posts, err := s.List(t.Context(), models.PostQuery{Limit: 2, Offset: 2, SortBy: models.SortByCreatedAt})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		draft, scheduled := posts[0], posts[1]
+		if draft.Status != models.StatusDraft || !draft.PublishAt.IsZero() {
+			t.Errorf("Got draft %+v", draft)
+		}
+		if scheduled.Status != models.StatusScheduled || !scheduled.PublishAt.Equal(base.Add(48*time.Hour)) {
+			t.Errorf("Got scheduled %+v", scheduled)
+		}
+	})
+
+	t.Run("find by title ignores case", func(t *testing.T) {
+		s := seed(t)
+		posts, err := s.FindByTitle(t.Context(), "DELTA")
+		if err != nil {
+			t.Fatalf("FindByTitle: %v", err)
+		}
+		if len(posts) != 1 || posts[0].Name != "Delta" || !slices.Equal(posts[0].Tags, []string{"go", "sql"}) {
+			t.Errorf("Got %+v", posts)
+		}
+
+		posts, err = s.FindByTitle(t.Context(), "Delt")
+		if err != nil || len(posts) != 0 {
+			t.Errorf("Expected no match for a prefix, got %+v, %v", posts, err)
+		}
+	})
+
+	t.Run("tag counts", func(t *testing.T) {
+		s := seed(t)
+		counts, err := s.TagCounts(t.Context())
+		if err != nil {
+			t.Fatalf("TagCounts: %v", err)
+		}
+		// only published posts count, Bravo is still scheduled
+		want := []models.TagCount{{Tag: "go", Count: 3}, {Tag: "sql", Count: 1}}
+		if !slices.Equal(counts, want) {
+			t.Errorf("Got %v wanted %v", counts, want)
+		}
+	})
+}
+
+func TestInMemoryStore_List(t *testing.T) {
+	runListTests(t, func(t *testing.T) postLister { return NewInMemoryStore() })
+}
+
+func TestSQLiteStore_List(t *testing.T) {
+	runListTests(t, func(t *testing.T) postLister { return newTestSQLiteStore(t) })
+}
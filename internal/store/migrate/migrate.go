@@ -0,0 +1,215 @@
+// Package migrate applies versioned SQL migrations to a database/sql handle.
+//
+// Migrations live in an fs.FS (normally an embed.FS) as pairs of files:
+//
+//	0001_create_posts.up.sql
+//	0001_create_posts.down.sql
+//
+// The number is the version, the rest of the name is just for humans.
+// Applied versions are recorded in the schema_migrations table so running Up twice is a no-op.
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingUp   = errors.New("migration is missing its .up.sql file")
+	ErrMissingDown = errors.New("migration is missing its .down.sql file")
+	ErrBadFileName = errors.New("migration file name must look like 0001_name.up.sql")
+)
+
+const createTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Migration is one schema change and how to undo it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator runs migrations against a database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads every migration found in dir of fsys, sorted by version
+func New(db *sql.DB, fsys fs.FS, dir string) (*Migrator, error) {
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads and pairs up the .up.sql/.down.sql files in dir
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir %q: %w", dir, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		version, name, direction, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("version %d: %w", m.Version, ErrMissingUp)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("version %d: %w", m.Version, ErrMissingDown)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// parseFileName splits "0001_create_posts.up.sql" into 1, "create_posts", "up"
+func parseFileName(fileName string) (int, string, string, error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", ErrBadFileName
+	}
+	base = strings.TrimSuffix(base, direction)
+
+	number, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", ErrBadFileName
+	}
+	version, err := strconv.Atoi(number)
+	if err != nil || version <= 0 {
+		return 0, "", "", ErrBadFileName
+	}
+
+	return version, name, strings.TrimPrefix(direction, "."), nil
+}
+
+// Version returns the highest applied version, 0 when nothing has been applied
+func (m *Migrator) Version() (int, error) {
+	if _, err := m.db.Exec(createTable); err != nil {
+		return 0, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var version sql.NullInt64
+	if err := m.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Up applies every migration newer than the current version.
+// Each migration runs in its own transaction together with its schema_migrations row.
+func (m *Migrator) Up() error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		err := m.inTx(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+				migration.Version, migration.Name,
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migrate up to %d (%s): %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// Down rolls back the latest applied migration, it does nothing on an empty schema
+func (m *Migrator) Down() error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version != current {
+			continue
+		}
+
+		err := m.inTx(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migrate down from %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("applied version %d has no migration file", current)
+}
+
+func (m *Migrator) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,142 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+// The runner only speaks portable SQL so sqlite is good enough to exercise it here,
+// the postgres migrations themselves are covered by the integration tests in store.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+var testFS = fstest.MapFS{
+	"m/0001_create_things.up.sql":   {Data: []byte(`CREATE TABLE things (id TEXT PRIMARY KEY);`)},
+	"m/0001_create_things.down.sql": {Data: []byte(`DROP TABLE things;`)},
+	"m/0002_add_name.up.sql":        {Data: []byte(`ALTER TABLE things ADD COLUMN name TEXT;`)},
+	"m/0002_add_name.down.sql":      {Data: []byte(`ALTER TABLE things DROP COLUMN name;`)},
+	"m/README":                      {Data: []byte(`not a migration`)},
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	db := newTestDB(t)
+	m, err := New(db, testFS, "m")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	t.Run("up applies everything", func(t *testing.T) {
+		if err := m.Up(); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		assertVersion(t, m, 2)
+		if _, err := db.Exec(`INSERT INTO things (id, name) VALUES ('a', 'b')`); err != nil {
+			t.Errorf("schema not applied: %v", err)
+		}
+	})
+
+	t.Run("up again is a no-op", func(t *testing.T) {
+		if err := m.Up(); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		assertVersion(t, m, 2)
+	})
+
+	t.Run("down rolls back one step at a time", func(t *testing.T) {
+		if err := m.Down(); err != nil {
+			t.Fatalf("Down: %v", err)
+		}
+		assertVersion(t, m, 1)
+		if _, err := db.Exec(`INSERT INTO things (id, name) VALUES ('c', 'd')`); err == nil {
+			t.Error("expected name column to be gone")
+		}
+
+		if err := m.Down(); err != nil {
+			t.Fatalf("Down: %v", err)
+		}
+		assertVersion(t, m, 0)
+
+		// nothing left to roll back
+		if err := m.Down(); err != nil {
+			t.Fatalf("Down on empty schema: %v", err)
+		}
+	})
+}
+
+func TestMigrator_FailedMigrationRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	fsys := fstest.MapFS{
+		"m/0001_ok.up.sql":    {Data: []byte(`CREATE TABLE ok (id TEXT);`)},
+		"m/0001_ok.down.sql":  {Data: []byte(`DROP TABLE ok;`)},
+		"m/0002_bad.up.sql":   {Data: []byte(`CREATE TABLE bad (id TEXT); THIS IS NOT SQL;`)},
+		"m/0002_bad.down.sql": {Data: []byte(`DROP TABLE bad;`)},
+	}
+	m, err := New(db, fsys, "m")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := m.Up(); err == nil {
+		t.Fatal("expected error from broken migration")
+	}
+	assertVersion(t, m, 1)
+	if _, err := db.Exec(`SELECT * FROM bad`); err == nil {
+		t.Error("expected the half applied migration to be rolled back")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr error
+	}{
+		{
+			name:    "missing down",
+			fsys:    fstest.MapFS{"m/0001_x.up.sql": {Data: []byte(`SELECT 1`)}},
+			wantErr: ErrMissingDown,
+		},
+		{
+			name:    "missing up",
+			fsys:    fstest.MapFS{"m/0001_x.down.sql": {Data: []byte(`SELECT 1`)}},
+			wantErr: ErrMissingUp,
+		},
+		{
+			name:    "bad file name",
+			fsys:    fstest.MapFS{"m/create_x.up.sql": {Data: []byte(`SELECT 1`)}},
+			wantErr: ErrBadFileName,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(tc.fsys, "m")
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Got error %v wanted %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func assertVersion(t testing.TB, m *Migrator, want int) {
+	t.Helper()
+	got, err := m.Version()
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	if got != want {
+		t.Errorf("Got version %d wanted %d", got, want)
+	}
+}
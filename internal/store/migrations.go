@@ -0,0 +1,22 @@
+package store
+
+import (
+	"database/sql"
+	"embed"
+
+	"github.com/aziz-shoko/goblog/internal/store/migrate"
+)
+
+// Each SQL backend has its own directory of migrations, see the migrate package for the file naming
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// migrateUp brings the schema of db up to date with the migrations in migrations/<dir>
+func migrateUp(db *sql.DB, dir string) error {
+	m, err := migrate.New(db, migrationsFS, "migrations/"+dir)
+	if err != nil {
+		return err
+	}
+	return m.Up()
+}
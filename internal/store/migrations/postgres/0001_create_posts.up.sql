@@ -0,0 +1,7 @@
+-- ids stay TEXT rather than UUID so a malformed id is a plain "not found" instead of a cast error
+CREATE TABLE IF NOT EXISTS posts (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
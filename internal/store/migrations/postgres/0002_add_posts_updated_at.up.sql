@@ -0,0 +1,3 @@
+ALTER TABLE posts ADD COLUMN updated_at TIMESTAMPTZ;
+UPDATE posts SET updated_at = created_at;
+ALTER TABLE posts ALTER COLUMN updated_at SET NOT NULL;
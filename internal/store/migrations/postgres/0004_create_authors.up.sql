@@ -0,0 +1,10 @@
+CREATE TABLE authors (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	bio        TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+-- no foreign key, a post keeps its author id even if the author never set up a profile
+ALTER TABLE posts ADD COLUMN author_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX posts_author_id_idx ON posts (author_id, created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE comments (
+	id         TEXT PRIMARY KEY,
+	post_id    TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	parent_id  TEXT REFERENCES comments (id) ON DELETE CASCADE,
+	author_id  TEXT NOT NULL DEFAULT '',
+	body       TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX comments_post_id_idx ON comments (post_id, created_at);
@@ -0,0 +1,14 @@
+ALTER TABLE posts ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+
+-- posts from before slugs use their id, it is already unique and they get a real slug when their title is next edited
+UPDATE posts SET slug = id WHERE slug = '';
+
+CREATE UNIQUE INDEX posts_slug_idx ON posts (slug) WHERE slug <> '';
+
+-- slugs posts had before their title was edited, so old links can redirect
+CREATE TABLE post_slugs (
+	slug    TEXT PRIMARY KEY,
+	post_id TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX post_slugs_post_id_idx ON post_slugs (post_id);
@@ -0,0 +1,14 @@
+-- revisions are never updated, a restore adds a new one with the old title and content
+CREATE TABLE post_revisions (
+	post_id    TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	number     INTEGER NOT NULL,
+	name       TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	editor_id  TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (post_id, number)
+);
+
+-- posts from before revisions start their history with what they look like today
+INSERT INTO post_revisions (post_id, number, name, content, editor_id, created_at)
+SELECT id, 1, name, content, author_id, updated_at FROM posts;
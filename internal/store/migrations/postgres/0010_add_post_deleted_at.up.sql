@@ -0,0 +1,4 @@
+-- NULL for live posts, set when a post is moved to the trash
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX posts_deleted_at_idx ON posts (deleted_at);
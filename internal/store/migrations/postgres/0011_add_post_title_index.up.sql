@@ -0,0 +1,2 @@
+-- duplicate title checks compare titles case insensitively
+CREATE INDEX posts_lower_name_idx ON posts (lower(name));
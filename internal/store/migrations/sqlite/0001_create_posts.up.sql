@@ -0,0 +1,7 @@
+-- IF NOT EXISTS so databases created before migrations existed are adopted as-is
+CREATE TABLE IF NOT EXISTS posts (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE posts ADD COLUMN updated_at TIMESTAMP;
+UPDATE posts SET updated_at = created_at;
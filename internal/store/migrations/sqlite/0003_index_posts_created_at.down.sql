@@ -0,0 +1 @@
+DROP INDEX IF EXISTS posts_created_at_idx;
@@ -0,0 +1,2 @@
+-- GET /posts sorts and filters on created_at by default
+CREATE INDEX IF NOT EXISTS posts_created_at_idx ON posts (created_at, id);
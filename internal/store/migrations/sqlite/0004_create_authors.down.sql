@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS posts_author_id_idx;
+ALTER TABLE posts DROP COLUMN author_id;
+DROP TABLE IF EXISTS authors;
@@ -0,0 +1,11 @@
+ALTER TABLE posts ADD COLUMN category TEXT NOT NULL DEFAULT '';
+CREATE INDEX posts_category_idx ON posts (category);
+
+CREATE TABLE post_tags (
+	post_id TEXT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	tag     TEXT NOT NULL,
+	PRIMARY KEY (post_id, tag)
+);
+
+-- the primary key covers lookups by post, this one covers GET /tags/{tag}/posts and the counts
+CREATE INDEX post_tags_tag_idx ON post_tags (tag);
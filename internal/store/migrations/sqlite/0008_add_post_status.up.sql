@@ -0,0 +1,7 @@
+-- every post before this was public the moment it was created
+ALTER TABLE posts ADD COLUMN status TEXT NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN publish_at TIMESTAMP NOT NULL DEFAULT '';
+UPDATE posts SET publish_at = created_at;
+
+-- the scheduler looks for scheduled posts that are due
+CREATE INDEX posts_status_publish_at_idx ON posts (status, publish_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS posts_lower_name_idx;
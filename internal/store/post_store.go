@@ -1,53 +1,483 @@
 package store
 
 import (
-	"github.com/aziz-shoko/goblog/models"
+	"context"
 	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
 )
 
 var (
-	ErrNotFound = errors.New("Item not found")
+	ErrNotFound      = errors.New("Item not found")
+	ErrEmptyStore    = errors.New("Emtpy store")
+	ErrAlreadyExists = errors.New("Item already exists")
 )
 
+// InMemoryStore keeps posts in a map guarded by a RWMutex.
+// Trashed posts stay in the map with DeletedAt set until they are purged.
+// net/http serves every request on its own goroutine, so all access to the map
+// has to go through the lock. Reads take the shared lock, writes take the exclusive one.
+// Posts are copied on the way in and out so callers never share memory with the store.
 type InMemoryStore struct {
+	mu    sync.RWMutex
 	posts map[string]*models.Post
+	// slugs maps every slug a post has ever had to its id, so old URLs keep resolving
+	slugs map[string]string
+	// revisions is written under mu together with the posts, see CreateWithRevision
+	revisions *InMemoryRevisionStore
 }
 
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
-		posts: make(map[string]*models.Post),
+		posts:     make(map[string]*models.Post),
+		slugs:     make(map[string]string),
+		revisions: NewInMemoryRevisionStore(),
 	}
 }
 
-func (s *InMemoryStore) Create(post *models.Post) error {
+// Revisions returns the revision store CreateWithRevision and UpdateWithRevision write to
+func (s *InMemoryStore) Revisions() *InMemoryRevisionStore {
+	return s.revisions
+}
+
+func (s *InMemoryStore) Create(ctx context.Context, post *models.Post) error {
+	return s.CreateWithRevision(ctx, post, nil)
+}
+
+// CreateWithRevision stores the post and, unless rev is nil, its first revision.
+// Every check runs before anything is written, so either both are kept or neither is.
+func (s *InMemoryStore) CreateWithRevision(ctx context.Context, post *models.Post, rev *models.Revision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if post == nil {
 		return errors.New("post cannot be nil")
 	}
 
-	s.posts[post.ID] = post
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return nil	
+	if s.slugTaken(post.Slug, post.ID) {
+		return ErrAlreadyExists
+	}
+	s.posts[post.ID] = copyPost(post)
+	if post.Slug != "" {
+		s.slugs[post.Slug] = post.ID
+	}
+	if rev != nil {
+		s.revisions.add(rev)
+	}
+
+	return nil
 }
 
-func (s *InMemoryStore) GetByID(id string) (*models.Post, error) {
-	if _, ok := s.posts[id]; !ok {
+// GetBySlug finds a post by its current slug or any slug it had before a rename.
+// Callers can compare the returned post's Slug with the one they asked for to redirect.
+// Trashed posts are found too, their slugs stay reserved until they are purged.
+func (s *InMemoryStore) GetBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	post, ok := s.posts[s.slugs[slug]]
+	if !ok || slug == "" {
 		return nil, ErrNotFound
 	}
-	return s.posts[id], nil
+	return copyPost(post), nil
+}
+
+// slugTaken reports whether another post has, or used to have, slug. Callers hold the lock.
+func (s *InMemoryStore) slugTaken(slug, postID string) bool {
+	owner, ok := s.slugs[slug]
+	return slug != "" && ok && owner != postID
 }
 
-func (s *InMemoryStore) GetAll() ([]*models.Post, error) {
-	if len(s.posts) == 0 {
-		return nil, errors.New("Emtpy store")
+func (s *InMemoryStore) GetByID(ctx context.Context, id string) (*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	listOfPosts := []*models.Post{}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	post, ok := s.posts[id]
+	if !ok || !post.DeletedAt.IsZero() {
+		return nil, ErrNotFound
+	}
+	return copyPost(post), nil
+}
+
+// FindByTitle returns the live posts titled title, ignoring case
+func (s *InMemoryStore) FindByTitle(ctx context.Context, title string) ([]*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := []*models.Post{}
+	for _, post := range s.posts {
+		if post.DeletedAt.IsZero() && strings.EqualFold(post.Name, title) {
+			found = append(found, copyPost(post))
+		}
+	}
+	return found, nil
+}
+
+func (s *InMemoryStore) GetAll(ctx context.Context) ([]*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	listOfPosts := make([]*models.Post, 0, len(s.posts))
 	for _, val := range s.posts {
-		listOfPosts = append(listOfPosts, val)
+		if val.DeletedAt.IsZero() {
+			listOfPosts = append(listOfPosts, copyPost(val))
+		}
+	}
+	if len(listOfPosts) == 0 {
+		return nil, ErrEmptyStore
 	}
 	return listOfPosts, nil
 }
 
-func (s *InMemoryStore) DeleteAll() error {
-	s.posts = make(map[string]*models.Post)	
+// List returns one page of posts, an empty page is not an error.
+// The query is expected to have gone through PostQuery.Validate already.
+func (s *InMemoryStore) List(ctx context.Context, q models.PostQuery) ([]*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	matched := make([]*models.Post, 0, len(s.posts))
+	for _, post := range s.posts {
+		if !post.DeletedAt.IsZero() {
+			continue
+		}
+		if q.AuthorID != "" && post.AuthorID != q.AuthorID {
+			continue
+		}
+		if q.Category != "" && post.Category != q.Category {
+			continue
+		}
+		if q.Tag != "" && !slices.Contains(post.Tags, q.Tag) {
+			continue
+		}
+		if q.Status != "" && post.Status != q.Status {
+			continue
+		}
+		if q.PublicOnly && !post.IsPublic() && (q.Viewer == "" || post.AuthorID != q.Viewer) {
+			continue
+		}
+		if !q.PublishDueBy.IsZero() && post.PublishAt.After(q.PublishDueBy) {
+			continue
+		}
+		if !q.CreatedAfter.IsZero() && !post.CreatedAt.After(q.CreatedAfter) {
+			continue
+		}
+		if !q.CreatedBefore.IsZero() && !post.CreatedAt.Before(q.CreatedBefore) {
+			continue
+		}
+		matched = append(matched, copyPost(post))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if q.Descending {
+			a, b = b, a
+		}
+		// ID breaks ties so pages never overlap or skip posts, same as the SQL stores
+		switch q.SortBy {
+		case models.SortByName:
+			an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name)
+			if an != bn {
+				return an < bn
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	})
+
+	if q.Offset >= len(matched) {
+		return []*models.Post{}, nil
+	}
+	matched = matched[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(matched) {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
+
+// TagCounts returns every tag on published posts with its number of posts, most used first
+func (s *InMemoryStore) TagCounts(ctx context.Context) ([]models.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	counts := map[string]int{}
+	for _, post := range s.posts {
+		if !post.IsPublic() || !post.DeletedAt.IsZero() {
+			continue
+		}
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+	}
+	s.mu.RUnlock()
+
+	tags := make([]models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, models.TagCount{Tag: tag, Count: count})
+	}
+	sortTagCounts(tags)
+	return tags, nil
+}
+
+// Counts returns how many live posts there are in each status, and how many are in the trash
+func (s *InMemoryStore) Counts(ctx context.Context) (models.PostCounts, error) {
+	if err := ctx.Err(); err != nil {
+		return models.PostCounts{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := models.PostCounts{ByStatus: map[models.PostStatus]int{}}
+	for _, post := range s.posts {
+		if !post.DeletedAt.IsZero() {
+			counts.Trashed++
+			continue
+		}
+		counts.ByStatus[post.Status]++
+	}
+	return counts, nil
+}
+
+// sortTagCounts orders by count descending then name, same as the SQL stores
+func sortTagCounts(tags []models.TagCount) {
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+}
+
+// Update replaces an existing post, it never creates one and never touches a trashed one
+func (s *InMemoryStore) Update(ctx context.Context, post *models.Post) error {
+	return s.UpdateWithRevision(ctx, post, nil)
+}
+
+// UpdateWithRevision is Update that also records rev unless it is nil, both or neither are kept
+func (s *InMemoryStore) UpdateWithRevision(ctx context.Context, post *models.Post, rev *models.Revision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if post == nil {
+		return errors.New("post cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.posts[post.ID]; !ok || !existing.DeletedAt.IsZero() {
+		return ErrNotFound
+	}
+	if s.slugTaken(post.Slug, post.ID) {
+		return ErrAlreadyExists
+	}
+	s.posts[post.ID] = copyPost(post)
+	// the old slug stays in the map and keeps pointing here
+	if post.Slug != "" {
+		s.slugs[post.Slug] = post.ID
+	}
+	if rev != nil {
+		s.revisions.add(rev)
+	}
+	return nil
+}
+
+// Trash moves a live post to the trash, it disappears from every read but GetBySlug and ListTrash
+func (s *InMemoryStore) Trash(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok || !post.DeletedAt.IsZero() {
+		return ErrNotFound
+	}
+	post.DeletedAt = at
+	return nil
+}
+
+// TrashAll moves every live post to the trash
+func (s *InMemoryStore) TrashAll(ctx context.Context, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, post := range s.posts {
+		if post.DeletedAt.IsZero() {
+			post.DeletedAt = at
+		}
+	}
+	return nil
+}
+
+// Restore takes a post back out of the trash, ErrNotFound unless it is in there
+func (s *InMemoryStore) Restore(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok || post.DeletedAt.IsZero() {
+		return ErrNotFound
+	}
+	post.DeletedAt = time.Time{}
+	return nil
+}
+
+// GetTrashed finds a trashed post by id, live posts are ErrNotFound
+func (s *InMemoryStore) GetTrashed(ctx context.Context, id string) (*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	post, ok := s.posts[id]
+	if !ok || post.DeletedAt.IsZero() {
+		return nil, ErrNotFound
+	}
+	return copyPost(post), nil
+}
+
+// ListTrash returns one page of trashed posts, most recently deleted first
+func (s *InMemoryStore) ListTrash(ctx context.Context, q models.TrashQuery) ([]*models.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	trashed := []*models.Post{}
+	for _, post := range s.posts {
+		if !post.DeletedAt.IsZero() && (q.AuthorID == "" || post.AuthorID == q.AuthorID) {
+			trashed = append(trashed, copyPost(post))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(trashed, func(i, j int) bool {
+		if !trashed[i].DeletedAt.Equal(trashed[j].DeletedAt) {
+			return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+		}
+		return trashed[i].ID < trashed[j].ID
+	})
+
+	if q.Offset >= len(trashed) {
+		return []*models.Post{}, nil
+	}
+	trashed = trashed[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(trashed) {
+		trashed = trashed[:q.Limit]
+	}
+	return trashed, nil
+}
+
+// Purge permanently deletes every post trashed before deletedBefore and returns their ids
+func (s *InMemoryStore) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := []string{}
+	for id, post := range s.posts {
+		if !post.DeletedAt.IsZero() && post.DeletedAt.Before(deletedBefore) {
+			s.delete(id)
+			purged = append(purged, id)
+		}
+	}
+	sort.Strings(purged)
+	return purged, nil
+}
+
+// Delete permanently removes a post, live or trashed
+func (s *InMemoryStore) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[id]; !ok {
+		return ErrNotFound
+	}
+	s.delete(id)
 	return nil
-}
\ No newline at end of file
+}
+
+// delete drops the post and frees its slugs. Callers hold the lock.
+func (s *InMemoryStore) delete(id string) {
+	delete(s.posts, id)
+	for slug, owner := range s.slugs {
+		if owner == id {
+			delete(s.slugs, slug)
+		}
+	}
+}
+
+// DeleteAll permanently removes every post, trashed ones included
+func (s *InMemoryStore) DeleteAll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.posts = make(map[string]*models.Post)
+	s.slugs = make(map[string]string)
+	return nil
+}
+
+// copyPost returns a copy so the caller can't mutate what the store holds
+func copyPost(post *models.Post) *models.Post {
+	cp := *post
+	cp.Tags = slices.Clone(post.Tags)
+	return &cp
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// These tests are only meaningful with the race detector on:
+//   go test -race ./internal/store/...
+// Without -race they still catch "concurrent map writes" panics, but the detector
+// is what proves there are no unsynchronized reads either.
+
+const (
+	stressWorkers = 16
+	stressOps     = 200
+)
+
+func TestInMemoryStore_ConcurrentCreate(t *testing.T) {
+	s := NewInMemoryStore()
+
+	var wg sync.WaitGroup
+	for w := range stressWorkers {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range stressOps {
+				post, err := models.NewPost("Title"+strconv.Itoa(worker)+"-"+strconv.Itoa(i), "Some content")
+				if err != nil {
+					t.Errorf("NewPost: %v", err)
+					return
+				}
+				if err := s.Create(t.Context(), post); err != nil {
+					t.Errorf("Create: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	posts, err := s.GetAll(t.Context())
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(posts) != stressWorkers*stressOps {
+		t.Errorf("Expected %d posts, got %d", stressWorkers*stressOps, len(posts))
+	}
+}
+
+func TestInMemoryStore_ConcurrentMixedAccess(t *testing.T) {
+	s := NewInMemoryStore()
+
+	// seed some posts so readers have something to look up
+	seeded := []string{}
+	for i := range 10 {
+		post, _ := models.NewPost("Seed"+strconv.Itoa(i), "Seed content")
+		s.Create(t.Context(), post)
+		seeded = append(seeded, post.ID)
+	}
+
+	var wg sync.WaitGroup
+	for w := range stressWorkers {
+		wg.Add(4)
+
+		// writer
+		go func(worker int) {
+			defer wg.Done()
+			for i := range stressOps {
+				post, _ := models.NewPost("Title"+strconv.Itoa(worker)+"-"+strconv.Itoa(i), "Some content")
+				s.Create(t.Context(), post)
+			}
+		}(w)
+
+		// point reader, the seeded posts may be wiped by DeleteAll so ErrNotFound is fine
+		go func() {
+			defer wg.Done()
+			for i := range stressOps {
+				_, err := s.GetByID(t.Context(), seeded[i%len(seeded)])
+				if err != nil && err != ErrNotFound {
+					t.Errorf("GetByID: unexpected error %v", err)
+					return
+				}
+			}
+		}()
+
+		// list reader
+		go func() {
+			defer wg.Done()
+			for range stressOps {
+				posts, _ := s.GetAll(t.Context())
+				for _, p := range posts {
+					_ = p.Name
+				}
+			}
+		}()
+
+		// wiper
+		go func() {
+			defer wg.Done()
+			for range stressOps / 20 {
+				if err := s.DeleteAll(t.Context()); err != nil {
+					t.Errorf("DeleteAll: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestInMemoryStore_ReturnedPostsAreCopies(t *testing.T) {
+	s := NewInMemoryStore()
+	post, _ := models.NewPost("Original", "Original content")
+	s.Create(t.Context(), post)
+
+	// mutating the caller's pointer after Create must not leak into the store
+	post.Name = "Changed after create"
+
+	got, err := s.GetByID(t.Context(), post.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Name != "Original" {
+		t.Errorf("Expected stored name %q, got %q", "Original", got.Name)
+	}
+
+	// and mutating what GetByID returned must not either
+	got.Name = "Changed after get"
+	again, _ := s.GetByID(t.Context(), post.ID)
+	if again.Name != "Original" {
+		t.Errorf("Expected stored name %q, got %q", "Original", again.Name)
+	}
+}
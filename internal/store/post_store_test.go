@@ -1,10 +1,13 @@
 package store
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/aziz-shoko/goblog/models"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestPostStore_GetByID_Create(t *testing.T) {
@@ -36,7 +39,7 @@ func TestPostStore_GetByID_Create(t *testing.T) {
 			}(),
 			wantErr: false,
 			validate: func(s *InMemoryStore, post *models.Post) error {
-				got, err := s.GetByID(post.ID)
+				got, err := s.GetByID(t.Context(), post.ID)
 				if err != nil {
 					return fmt.Errorf("GetByID failed: %v", err)
 				}
@@ -59,7 +62,7 @@ func TestPostStore_GetByID_Create(t *testing.T) {
 			// false because we are not expecting error until validate
 			wantErr: false,
 			validate: func(s *InMemoryStore, post *models.Post) error {
-				_, err := s.GetByID("somerandomnonsenseid")
+				_, err := s.GetByID(t.Context(), "somerandomnonsenseid")
 				if err == nil {
 					return fmt.Errorf("GetByID failed")
 				}
@@ -74,7 +77,7 @@ func TestPostStore_GetByID_Create(t *testing.T) {
 	for _, sc := range scenarios {
 		t.Run(sc.name, func(t *testing.T) {
 			store := sc.setup()
-			err := store.Create(sc.post)
+			err := store.Create(t.Context(), sc.post)
 			if sc.wantErr && err == nil {
 				t.Fatalf("expected error, got nil")
 			}
@@ -101,26 +104,112 @@ func TestPostStore_Get_Delete(t *testing.T) {
 		title := "Title" + strconv.Itoa(i)
 		content := "Test Content" + strconv.Itoa(i)
 		post, _ := models.NewPost(title, content)
-		database.Create(post)
+		database.Create(t.Context(), post)
 	}
 
 	t.Run("Valid Get all test", func(t *testing.T) {
-		listOfPosts, _ := database.GetAll()
+		listOfPosts, _ := database.GetAll(t.Context())
 		if len(listOfPosts) != 5 {
 			t.Errorf("Expected 5 posts, got %d", len(listOfPosts))
 		}
 	})
 
 	t.Run("Delete all posts", func(t *testing.T) {
-		err := database.DeleteAll()
+		err := database.DeleteAll(t.Context())
 		if err != nil {
 			t.Fatalf("Expected to delete all posts but failed: %v", err)
 		}
 
-		if _, err := database.GetAll(); err == nil {
+		if _, err := database.GetAll(t.Context()); err == nil {
 			t.Error("Failed to delete all posts in database")
 		}
 
 	})
 }
 
+func TestPostStore_Update(t *testing.T) {
+	database := NewInMemoryStore()
+	post, _ := models.NewPost("Title", "Test Content")
+	database.Create(t.Context(), post)
+
+	t.Run("update existing post", func(t *testing.T) {
+		post.Edit("New Title", "New Content", time.Now())
+		if err := database.Update(t.Context(), post); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, _ := database.GetByID(t.Context(), post.ID)
+		if got.Name != "New Title" || got.Content != "New Content" {
+			t.Errorf("update not stored, got %+v", got)
+		}
+	})
+
+	t.Run("update missing post", func(t *testing.T) {
+		missing, _ := models.NewPost("Missing", "Not in the store")
+		if err := database.Update(t.Context(), missing); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+		if _, err := database.GetByID(t.Context(), missing.ID); err != ErrNotFound {
+			t.Errorf("Update must not create posts")
+		}
+	})
+}
+
+func TestPostStore_Delete(t *testing.T) {
+	database := NewInMemoryStore()
+	post, _ := models.NewPost("Title", "Test Content")
+	other, _ := models.NewPost("Other", "Other Content")
+	database.Create(t.Context(), post)
+	database.Create(t.Context(), other)
+
+	if err := database.Delete(t.Context(), post.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := database.GetByID(t.Context(), post.ID); err != ErrNotFound {
+		t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+	}
+	if _, err := database.GetByID(t.Context(), other.ID); err != nil {
+		t.Errorf("Delete removed the wrong post: %v", err)
+	}
+	if err := database.Delete(t.Context(), post.ID); err != ErrNotFound {
+		t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+	}
+}
+
+func TestPostStore_HonorsCancellation(t *testing.T) {
+	stores := []struct {
+		name  string
+		store interface {
+			Create(context.Context, *models.Post) error
+			GetAll(context.Context) ([]*models.Post, error)
+			List(context.Context, models.PostQuery) ([]*models.Post, error)
+		}
+	}{
+		{name: "in memory", store: NewInMemoryStore()},
+		{name: "sqlite", store: newTestSQLiteStore(t)},
+	}
+
+	for _, tc := range stores {
+		t.Run(tc.name, func(t *testing.T) {
+			post, _ := models.NewPost("Post", "Content")
+			if err := tc.store.Create(t.Context(), post); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel()
+			if err := tc.store.Create(ctx, post); !errors.Is(err, context.Canceled) {
+				t.Errorf("Create: got error %v wanted error %v", err, context.Canceled)
+			}
+			if _, err := tc.store.GetAll(ctx); !errors.Is(err, context.Canceled) {
+				t.Errorf("GetAll: got error %v wanted error %v", err, context.Canceled)
+			}
+
+			ctx, cancel = context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+			defer cancel()
+			if _, err := tc.store.List(ctx, models.PostQuery{Limit: 10}); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("List: got error %v wanted error %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
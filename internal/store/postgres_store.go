@@ -0,0 +1,43 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	// registers the "pgx" database/sql driver
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresStore persists posts in a PostgreSQL database
+type PostgresStore struct {
+	*sqlStore
+}
+
+// NewPostgresStore connects using dsn (a postgres:// URL or key=value string),
+// checks the connection and runs any pending migrations
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping postgres: %w", err)
+	}
+
+	if err := migrateUp(db, "postgres"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate postgres: %w", err)
+	}
+
+	return &PostgresStore{
+		sqlStore: &sqlStore{
+			db: db,
+			timeArg: func(t time.Time) any {
+				return t.UTC()
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// InMemoryRevisionStore keeps every post's revisions in a slice, oldest first
+type InMemoryRevisionStore struct {
+	mu        sync.RWMutex
+	revisions map[string][]models.Revision
+}
+
+func NewInMemoryRevisionStore() *InMemoryRevisionStore {
+	return &InMemoryRevisionStore{
+		revisions: make(map[string][]models.Revision),
+	}
+}
+
+// Create numbers the revision after the post's latest one and stores a copy
+func (s *InMemoryRevisionStore) Create(ctx context.Context, rev *models.Revision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if rev == nil {
+		return errors.New("revision cannot be nil")
+	}
+
+	s.add(rev)
+	return nil
+}
+
+// add is Create once the checks passed, InMemoryStore calls it while holding its own lock
+func (s *InMemoryRevisionStore) add(rev *models.Revision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rev.Number = len(s.revisions[rev.PostID]) + 1
+	s.revisions[rev.PostID] = append(s.revisions[rev.PostID], *rev)
+}
+
+// ListByPost returns the post's revisions oldest first, a post without any is not an error
+func (s *InMemoryRevisionStore) ListByPost(ctx context.Context, postID string) ([]*models.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := make([]*models.Revision, 0, len(s.revisions[postID]))
+	for _, rev := range s.revisions[postID] {
+		cp := rev
+		revisions = append(revisions, &cp)
+	}
+	return revisions, nil
+}
+
+func (s *InMemoryRevisionStore) GetByNumber(ctx context.Context, postID string, number int) (*models.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := s.revisions[postID]
+	if number < 1 || number > len(revisions) {
+		return nil, ErrNotFound
+	}
+	cp := revisions[number-1]
+	return &cp, nil
+}
+
+func (s *InMemoryRevisionStore) DeleteByPost(ctx context.Context, postID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.revisions, postID)
+	return nil
+}
+
+func (s *InMemoryRevisionStore) DeleteAll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revisions = make(map[string][]models.Revision)
+	return nil
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+type revisionStore interface {
+	Create(context.Context, *models.Revision) error
+	ListByPost(context.Context, string) ([]*models.Revision, error)
+	GetByNumber(context.Context, string, int) (*models.Revision, error)
+	DeleteByPost(context.Context, string) error
+	DeleteAll(ctx context.Context) error
+}
+
+// runRevisionStoreTests gets posts created through createPost because the SQL stores enforce the foreign key
+func runRevisionStoreTests(t *testing.T, newStore func(t *testing.T) (revisionStore, func(context.Context, *models.Post) error)) {
+	t.Run("create numbers revisions per post", func(t *testing.T) {
+		s, createPost := newStore(t)
+		post, _ := models.NewPost("Post", "first")
+		other, _ := models.NewPost("Other", "Content")
+		createPost(t.Context(), post)
+		createPost(t.Context(), other)
+
+		if err := s.Create(t.Context(), nil); err == nil {
+			t.Fatal("expected error for nil revision, got nil")
+		}
+
+		for i, content := range []string{"first", "second", "third"} {
+			post.Content = content
+			rev := models.NewRevision(post, "alice")
+			if err := s.Create(t.Context(), rev); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if rev.Number != i+1 {
+				t.Errorf("expected revision number %d, got %d", i+1, rev.Number)
+			}
+		}
+		if rev := models.NewRevision(other, "bob"); s.Create(t.Context(), rev) != nil || rev.Number != 1 {
+			t.Errorf("expected the other post to start at revision 1, got %d", rev.Number)
+		}
+
+		revisions, err := s.ListByPost(t.Context(), post.ID)
+		if err != nil {
+			t.Fatalf("ListByPost: %v", err)
+		}
+		contents := []string{}
+		for _, rev := range revisions {
+			contents = append(contents, rev.Content)
+		}
+		if len(contents) != 3 || contents[0] != "first" || contents[2] != "third" {
+			t.Errorf("unexpected revisions %v", contents)
+		}
+
+		got, err := s.GetByNumber(t.Context(), post.ID, 2)
+		if err != nil {
+			t.Fatalf("GetByNumber: %v", err)
+		}
+		if got.Content != "second" || got.Name != "Post" || got.EditorID != "alice" || !got.CreatedAt.Equal(post.UpdatedAt) {
+			t.Errorf("stored revision mismatch: got %+v", got)
+		}
+		for _, number := range []int{0, 4} {
+			if _, err := s.GetByNumber(t.Context(), post.ID, number); err != ErrNotFound {
+				t.Errorf("revision %d: got error %v wanted error %v", number, err, ErrNotFound)
+			}
+		}
+		if revisions, err := s.ListByPost(t.Context(), "nope"); err != nil || len(revisions) != 0 {
+			t.Errorf("expected no revisions for an unknown post, got %d, %v", len(revisions), err)
+		}
+	})
+
+	t.Run("delete by post and delete all", func(t *testing.T) {
+		s, createPost := newStore(t)
+		post, _ := models.NewPost("Post", "Content")
+		other, _ := models.NewPost("Other", "Content")
+		createPost(t.Context(), post)
+		createPost(t.Context(), other)
+		s.Create(t.Context(), models.NewRevision(post, ""))
+		s.Create(t.Context(), models.NewRevision(other, ""))
+
+		if err := s.DeleteByPost(t.Context(), post.ID); err != nil {
+			t.Fatalf("DeleteByPost: %v", err)
+		}
+		if revisions, _ := s.ListByPost(t.Context(), post.ID); len(revisions) != 0 {
+			t.Errorf("expected no revisions left, got %d", len(revisions))
+		}
+		if revisions, _ := s.ListByPost(t.Context(), other.ID); len(revisions) != 1 {
+			t.Errorf("DeleteByPost removed revisions of another post")
+		}
+
+		if err := s.DeleteAll(t.Context()); err != nil {
+			t.Fatalf("DeleteAll: %v", err)
+		}
+		if revisions, _ := s.ListByPost(t.Context(), other.ID); len(revisions) != 0 {
+			t.Errorf("expected no revisions left, got %d", len(revisions))
+		}
+	})
+}
+
+// revisionedPostStore is a post store that keeps the revisions of its own posts
+type revisionedPostStore interface {
+	GetByID(context.Context, string) (*models.Post, error)
+	Create(context.Context, *models.Post) error
+	CreateWithRevision(context.Context, *models.Post, *models.Revision) error
+	UpdateWithRevision(context.Context, *models.Post, *models.Revision) error
+}
+
+func runPostWithRevisionTests(t *testing.T, newStore func(t *testing.T) (revisionedPostStore, revisionStore)) {
+	t.Run("post and revision are written together", func(t *testing.T) {
+		s, revisions := newStore(t)
+		post, _ := models.NewPost("Post", "first")
+
+		if err := s.CreateWithRevision(t.Context(), post, models.NewRevision(post, "alice")); err != nil {
+			t.Fatalf("CreateWithRevision: %v", err)
+		}
+		post.Edit("Post", "second", time.Now())
+		if err := s.UpdateWithRevision(t.Context(), post, models.NewRevision(post, "bob")); err != nil {
+			t.Fatalf("UpdateWithRevision: %v", err)
+		}
+		// a nil revision only saves the post
+		post.Edit("Post", "third", time.Now())
+		if err := s.UpdateWithRevision(t.Context(), post, nil); err != nil {
+			t.Fatalf("UpdateWithRevision: %v", err)
+		}
+
+		if got, _ := s.GetByID(t.Context(), post.ID); got == nil || got.Content != "third" {
+			t.Errorf("expected the post to be saved, got %+v", got)
+		}
+		got, err := revisions.ListByPost(t.Context(), post.ID)
+		if err != nil {
+			t.Fatalf("ListByPost: %v", err)
+		}
+		if len(got) != 2 || got[0].EditorID != "alice" || got[1].Content != "second" {
+			t.Errorf("unexpected revisions %+v", got)
+		}
+	})
+
+	t.Run("a rejected post keeps no revision", func(t *testing.T) {
+		s, revisions := newStore(t)
+		taken, _ := models.NewPost("Taken", "Content")
+		s.Create(t.Context(), taken)
+
+		post, _ := models.NewPost("Taken", "Content")
+		if err := s.CreateWithRevision(t.Context(), post, models.NewRevision(post, "")); err != ErrAlreadyExists {
+			t.Errorf("got error %v wanted error %v", err, ErrAlreadyExists)
+		}
+		missing, _ := models.NewPost("Missing", "Content")
+		if err := s.UpdateWithRevision(t.Context(), missing, models.NewRevision(missing, "")); err != ErrNotFound {
+			t.Errorf("got error %v wanted error %v", err, ErrNotFound)
+		}
+		for _, id := range []string{post.ID, missing.ID} {
+			if got, _ := revisions.ListByPost(t.Context(), id); len(got) != 0 {
+				t.Errorf("expected no revisions, got %d", len(got))
+			}
+		}
+	})
+}
+
+func TestInMemoryStore_PostWithRevision(t *testing.T) {
+	runPostWithRevisionTests(t, func(t *testing.T) (revisionedPostStore, revisionStore) {
+		s := NewInMemoryStore()
+		return s, s.Revisions()
+	})
+}
+
+func TestSQLiteStore_PostWithRevision(t *testing.T) {
+	runPostWithRevisionTests(t, func(t *testing.T) (revisionedPostStore, revisionStore) {
+		s := newTestSQLiteStore(t)
+		return s, s.Revisions()
+	})
+}
+
+func TestInMemoryRevisionStore(t *testing.T) {
+	runRevisionStoreTests(t, func(t *testing.T) (revisionStore, func(context.Context, *models.Post) error) {
+		return NewInMemoryRevisionStore(), NewInMemoryStore().Create
+	})
+}
+
+func TestSQLiteRevisionStore(t *testing.T) {
+	runRevisionStoreTests(t, func(t *testing.T) (revisionStore, func(context.Context, *models.Post) error) {
+		s := newTestSQLiteStore(t)
+		return s.Revisions(), s.Create
+	})
+}
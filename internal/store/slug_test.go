@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// slugStore is the part of the store API the slug tests need,
+// so the same cases run against the in-memory store and every SQL backend
+type slugStore interface {
+	Create(context.Context, *models.Post) error
+	Update(context.Context, *models.Post) error
+	Delete(ctx context.Context, id string) error
+	GetBySlug(ctx context.Context, slug string) (*models.Post, error)
+}
+
+func runSlugTests(t *testing.T, newStore func(t *testing.T) slugStore) {
+	t.Run("current and old slugs resolve", func(t *testing.T) {
+		s := newStore(t)
+
+		post, _ := models.NewPost("First title", "Some content")
+		if err := s.Create(t.Context(), post); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		post.Edit("Second title", "Some content", time.Now())
+		if err := s.Update(t.Context(), post); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		for _, slug := range []string{"first-title", "second-title"} {
+			got, err := s.GetBySlug(t.Context(), slug)
+			if err != nil {
+				t.Fatalf("GetBySlug(%q): %v", slug, err)
+			}
+			if got.ID != post.ID || got.Slug != "second-title" {
+				t.Errorf("GetBySlug(%q) got %s with slug %q", slug, got.ID, got.Slug)
+			}
+		}
+
+		// going back to an old title must not trip over its own history
+		post.Edit("First title", "Some content", time.Now())
+		if err := s.Update(t.Context(), post); err != nil {
+			t.Fatalf("Update back: %v", err)
+		}
+		if got, err := s.GetBySlug(t.Context(), "first-title"); err != nil || got.Slug != "first-title" {
+			t.Errorf("expected first-title to be current again, got %+v, %v", got, err)
+		}
+	})
+
+	t.Run("slugs are unique, old ones included", func(t *testing.T) {
+		s := newStore(t)
+
+		post, _ := models.NewPost("Taken", "Some content")
+		s.Create(t.Context(), post)
+		post.Edit("Renamed", "Some content", time.Now())
+		s.Update(t.Context(), post)
+
+		for _, slug := range []string{"taken", "renamed"} {
+			other, _ := models.NewPost("Other", "Other content")
+			other.Slug = slug
+			if err := s.Create(t.Context(), other); err != ErrAlreadyExists {
+				t.Errorf("Create with slug %q: got error %v wanted %v", slug, err, ErrAlreadyExists)
+			}
+		}
+
+		other, _ := models.NewPost("Other", "Other content")
+		s.Create(t.Context(), other)
+		other.Slug = "renamed"
+		if err := s.Update(t.Context(), other); err != ErrAlreadyExists {
+			t.Errorf("Update: got error %v wanted %v", err, ErrAlreadyExists)
+		}
+	})
+
+	t.Run("deleting frees the slugs", func(t *testing.T) {
+		s := newStore(t)
+
+		post, _ := models.NewPost("Gone soon", "Some content")
+		s.Create(t.Context(), post)
+		s.Delete(t.Context(), post.ID)
+
+		if _, err := s.GetBySlug(t.Context(), "gone-soon"); err != ErrNotFound {
+			t.Errorf("Got error %v wanted %v", err, ErrNotFound)
+		}
+		again, _ := models.NewPost("Gone soon", "Some content")
+		if err := s.Create(t.Context(), again); err != nil {
+			t.Errorf("slug not freed: %v", err)
+		}
+	})
+
+	t.Run("unknown slug", func(t *testing.T) {
+		if _, err := newStore(t).GetBySlug(t.Context(), "nope"); err != ErrNotFound {
+			t.Errorf("Got error %v wanted %v", err, ErrNotFound)
+		}
+	})
+}
+
+func TestInMemoryStore_Slugs(t *testing.T) {
+	runSlugTests(t, func(t *testing.T) slugStore { return NewInMemoryStore() })
+}
+
+func TestSQLiteStore_Slugs(t *testing.T) {
+	runSlugTests(t, func(t *testing.T) slugStore { return newTestSQLiteStore(t) })
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// SQLAuthorStore keeps authors in the same database as the posts, get one from SQLiteStore.Authors or PostgresStore.Authors
+type SQLAuthorStore struct {
+	*sqlStore
+}
+
+// Create adds an author, ErrAlreadyExists if the ID is taken
+func (s *SQLAuthorStore) Create(ctx context.Context, author *models.Author) error {
+	if author == nil {
+		return errors.New("author cannot be nil")
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO authors (id, name, bio, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO NOTHING`,
+		author.ID, author.Name, author.Bio, s.timeArg(author.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res); errors.Is(err, ErrNotFound) {
+		return ErrAlreadyExists
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *SQLAuthorStore) GetByID(ctx context.Context, id string) (*models.Author, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+authorColumns+` FROM authors WHERE id = $1`, id)
+
+	author, err := scanAuthor(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// List returns every author sorted by name, an empty store gives an empty list
+func (s *SQLAuthorStore) List(ctx context.Context) ([]*models.Author, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+authorColumns+` FROM authors ORDER BY LOWER(name), id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors := []*models.Author{}
+	for rows.Next() {
+		author, err := scanAuthor(rows)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+	return authors, rows.Err()
+}
+
+// authorColumns must stay in the same order as the Scan call in scanAuthor
+const authorColumns = `id, name, bio, created_at`
+
+func scanAuthor(row scanner) (*models.Author, error) {
+	var author models.Author
+	var createdAt time.Time
+	if err := row.Scan(&author.ID, &author.Name, &author.Bio, &createdAt); err != nil {
+		return nil, err
+	}
+	author.CreatedAt = createdAt.UTC()
+	return &author, nil
+}
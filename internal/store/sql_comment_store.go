@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// SQLCommentStore keeps comments in the same database as the posts, get one from SQLiteStore.Comments or PostgresStore.Comments.
+// The comments table cascades on post deletion, DeleteByPost is still there for the service to call explicitly.
+type SQLCommentStore struct {
+	*sqlStore
+}
+
+func (s *SQLCommentStore) Create(ctx context.Context, comment *models.Comment) error {
+	if comment == nil {
+		return errors.New("comment cannot be nil")
+	}
+
+	// parent_id is NULL rather than '' so the self referencing foreign key is satisfied
+	var parentID sql.NullString
+	if comment.ParentID != "" {
+		parentID = sql.NullString{String: comment.ParentID, Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO comments (id, post_id, parent_id, author_id, body, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		comment.ID, comment.PostID, parentID, comment.AuthorID, comment.Body, s.timeArg(comment.CreatedAt),
+	)
+	return err
+}
+
+func (s *SQLCommentStore) GetByID(ctx context.Context, id string) (*models.Comment, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = $1`, id)
+
+	comment, err := scanComment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ListByPost returns every comment on a post oldest first, replies included
+func (s *SQLCommentStore) ListByPost(ctx context.Context, postID string) ([]*models.Comment, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE post_id = $1 ORDER BY created_at, id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*models.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (s *SQLCommentStore) DeleteByPost(ctx context.Context, postID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM comments WHERE post_id = $1`, postID)
+	return err
+}
+
+func (s *SQLCommentStore) DeleteAll(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM comments`)
+	return err
+}
+
+// commentColumns must stay in the same order as the Scan call in scanComment
+const commentColumns = `id, post_id, parent_id, author_id, body, created_at`
+
+func scanComment(row scanner) (*models.Comment, error) {
+	var comment models.Comment
+	var parentID sql.NullString
+	var createdAt time.Time
+	if err := row.Scan(&comment.ID, &comment.PostID, &parentID, &comment.AuthorID, &comment.Body, &createdAt); err != nil {
+		return nil, err
+	}
+	comment.ParentID = parentID.String
+	comment.CreatedAt = createdAt.UTC()
+	return &comment, nil
+}
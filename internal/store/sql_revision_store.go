@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// SQLRevisionStore keeps revisions in the same database as the posts, get one from SQLiteStore.Revisions or PostgresStore.Revisions.
+// Like comments, the table cascades on post deletion.
+type SQLRevisionStore struct {
+	*sqlStore
+}
+
+// Create numbers the revision after the post's latest one, the primary key on (post_id, number)
+// turns a concurrent writer taking the same number into an error instead of a silent duplicate
+func (s *SQLRevisionStore) Create(ctx context.Context, rev *models.Revision) error {
+	if rev == nil {
+		return errors.New("revision cannot be nil")
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insertRevision(ctx, tx, rev)
+	})
+}
+
+// insertRevision writes rev inside tx, a nil rev is nothing to write.
+// CreateWithRevision and UpdateWithRevision share it so the post and its revision commit together.
+func (s *sqlStore) insertRevision(ctx context.Context, tx *sql.Tx, rev *models.Revision) error {
+	if rev == nil {
+		return nil
+	}
+
+	var number int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(number), 0) + 1 FROM post_revisions WHERE post_id = $1`, rev.PostID).Scan(&number); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO post_revisions (post_id, number, name, content, editor_id, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		rev.PostID, number, rev.Name, rev.Content, rev.EditorID, s.timeArg(rev.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	rev.Number = number
+	return nil
+}
+
+// ListByPost returns the post's revisions oldest first, a post without any is not an error
+func (s *SQLRevisionStore) ListByPost(ctx context.Context, postID string) ([]*models.Revision, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+revisionColumns+` FROM post_revisions WHERE post_id = $1 ORDER BY number`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*models.Revision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *SQLRevisionStore) GetByNumber(ctx context.Context, postID string, number int) (*models.Revision, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+revisionColumns+` FROM post_revisions WHERE post_id = $1 AND number = $2`, postID, number)
+
+	rev, err := scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+func (s *SQLRevisionStore) DeleteByPost(ctx context.Context, postID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM post_revisions WHERE post_id = $1`, postID)
+	return err
+}
+
+func (s *SQLRevisionStore) DeleteAll(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM post_revisions`)
+	return err
+}
+
+// revisionColumns must stay in the same order as the Scan call in scanRevision
+const revisionColumns = `post_id, number, name, content, editor_id, created_at`
+
+func scanRevision(row scanner) (*models.Revision, error) {
+	var rev models.Revision
+	var createdAt time.Time
+	if err := row.Scan(&rev.PostID, &rev.Number, &rev.Name, &rev.Content, &rev.EditorID, &createdAt); err != nil {
+		return nil, err
+	}
+	rev.CreatedAt = createdAt.UTC()
+	return &rev, nil
+}
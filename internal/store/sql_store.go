@@ -0,0 +1,527 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// sqlStore holds the queries shared by every database/sql backed store.
+// Queries use $N placeholders which both postgres and sqlite understand,
+// anything dialect specific is pushed into the fields below.
+type sqlStore struct {
+	db *sql.DB
+
+	// timeArg converts a time into whatever the driver should receive for a timestamp column
+	timeArg func(time.Time) any
+}
+
+// Close releases the underlying database handle
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// Authors returns an AuthorStore sharing this store's database
+func (s *sqlStore) Authors() *SQLAuthorStore {
+	return &SQLAuthorStore{sqlStore: s}
+}
+
+// Comments returns a CommentStore sharing this store's database
+func (s *sqlStore) Comments() *SQLCommentStore {
+	return &SQLCommentStore{sqlStore: s}
+}
+
+// Revisions returns a RevisionStore sharing this store's database
+func (s *sqlStore) Revisions() *SQLRevisionStore {
+	return &SQLRevisionStore{sqlStore: s}
+}
+
+func (s *sqlStore) Create(ctx context.Context, post *models.Post) error {
+	return s.CreateWithRevision(ctx, post, nil)
+}
+
+// CreateWithRevision inserts the post and, unless rev is nil, its first revision in one transaction
+func (s *sqlStore) CreateWithRevision(ctx context.Context, post *models.Post, rev *models.Revision) error {
+	if post == nil {
+		return errors.New("post cannot be nil")
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkSlug(ctx, tx, post.Slug, post.ID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO posts (id, name, content, slug, author_id, category, status, publish_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			post.ID, post.Name, post.Content, post.Slug, post.AuthorID, post.Category,
+			post.Status, s.timeArg(post.PublishAt), s.timeArg(post.CreatedAt), s.timeArg(post.UpdatedAt),
+		)
+		if err != nil {
+			return err
+		}
+		if err := replaceTags(ctx, tx, post.ID, post.Tags); err != nil {
+			return err
+		}
+		return s.insertRevision(ctx, tx, rev)
+	})
+}
+
+// List pushes the whole query down into SQL, an empty page is not an error.
+// The query is expected to have gone through PostQuery.Validate already.
+func (s *sqlStore) List(ctx context.Context, q models.PostQuery) ([]*models.Post, error) {
+	// trashed posts never show up in a listing
+	where := []string{"deleted_at IS NULL"}
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.AuthorID != "" {
+		where = append(where, "author_id = "+arg(q.AuthorID))
+	}
+	if q.Category != "" {
+		where = append(where, "category = "+arg(q.Category))
+	}
+	if q.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = posts.id AND t.tag = "+arg(q.Tag)+")")
+	}
+	if q.Status != "" {
+		where = append(where, "status = "+arg(q.Status))
+	}
+	if q.PublicOnly {
+		where = append(where, "(status = "+arg(models.StatusPublished)+" OR (author_id <> '' AND author_id = "+arg(q.Viewer)+"))")
+	}
+	if !q.PublishDueBy.IsZero() {
+		where = append(where, "publish_at <= "+arg(s.timeArg(q.PublishDueBy)))
+	}
+	if !q.CreatedAfter.IsZero() {
+		where = append(where, "created_at > "+arg(s.timeArg(q.CreatedAfter)))
+	}
+	if !q.CreatedBefore.IsZero() {
+		where = append(where, "created_at < "+arg(s.timeArg(q.CreatedBefore)))
+	}
+
+	query := `SELECT ` + postColumns + ` FROM posts WHERE ` + strings.Join(where, " AND ")
+
+	// only whitelisted column names ever end up in ORDER BY
+	orderBy := "created_at"
+	if q.SortBy == models.SortByName {
+		orderBy = "LOWER(name)"
+	}
+	direction := "ASC"
+	if q.Descending {
+		direction = "DESC"
+	}
+	// id breaks ties so pages never overlap or skip posts
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s`, orderBy, direction, direction)
+
+	// the query has been validated so Limit is always set, sqlite refuses OFFSET without LIMIT
+	query += ` LIMIT ` + arg(q.Limit) + ` OFFSET ` + arg(q.Offset)
+
+	return s.queryPosts(ctx, query, args...)
+}
+
+// TagCounts returns every tag on published posts with its number of posts, most used first
+func (s *sqlStore) TagCounts(ctx context.Context) ([]models.TagCount, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT t.tag, COUNT(*) FROM post_tags t JOIN posts p ON p.id = t.post_id WHERE p.status = $1 AND p.deleted_at IS NULL
+		GROUP BY t.tag ORDER BY COUNT(*) DESC, t.tag`,
+		models.StatusPublished,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.TagCount{}
+	for rows.Next() {
+		var tc models.TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tc)
+	}
+	return tags, rows.Err()
+}
+
+// Counts returns how many live posts there are in each status, and how many are in the trash
+func (s *sqlStore) Counts(ctx context.Context) (models.PostCounts, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT status, deleted_at IS NOT NULL, COUNT(*) FROM posts GROUP BY status, deleted_at IS NOT NULL`,
+	)
+	if err != nil {
+		return models.PostCounts{}, err
+	}
+	defer rows.Close()
+
+	counts := models.PostCounts{ByStatus: map[models.PostStatus]int{}}
+	for rows.Next() {
+		var (
+			status  models.PostStatus
+			trashed bool
+			n       int
+		)
+		if err := rows.Scan(&status, &trashed, &n); err != nil {
+			return models.PostCounts{}, err
+		}
+		if trashed {
+			counts.Trashed += n
+		} else {
+			counts.ByStatus[status] += n
+		}
+	}
+	return counts, rows.Err()
+}
+
+// Update replaces an existing post, it never creates one and never touches a trashed one
+func (s *sqlStore) Update(ctx context.Context, post *models.Post) error {
+	return s.UpdateWithRevision(ctx, post, nil)
+}
+
+// UpdateWithRevision is Update that also records rev unless it is nil, in the same transaction
+func (s *sqlStore) UpdateWithRevision(ctx context.Context, post *models.Post, rev *models.Revision) error {
+	if post == nil {
+		return errors.New("post cannot be nil")
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkSlug(ctx, tx, post.Slug, post.ID); err != nil {
+			return err
+		}
+		// keep the outgoing slug around for redirects, and drop the history entry if the post takes it back
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO post_slugs (slug, post_id) SELECT slug, id FROM posts WHERE id = $1 AND slug <> $2 AND slug <> ''
+			ON CONFLICT (slug) DO NOTHING`,
+			post.ID, post.Slug,
+		)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM post_slugs WHERE slug = $1`, post.Slug); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx,
+			`UPDATE posts SET name = $1, content = $2, slug = $3, category = $4, status = $5, publish_at = $6, updated_at = $7
+			WHERE id = $8 AND deleted_at IS NULL`,
+			post.Name, post.Content, post.Slug, post.Category, post.Status, s.timeArg(post.PublishAt), s.timeArg(post.UpdatedAt), post.ID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(res); err != nil {
+			return err
+		}
+		if err := replaceTags(ctx, tx, post.ID, post.Tags); err != nil {
+			return err
+		}
+		return s.insertRevision(ctx, tx, rev)
+	})
+}
+
+func (s *sqlStore) GetByID(ctx context.Context, id string) (*models.Post, error) {
+	posts, err := s.queryPosts(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, ErrNotFound
+	}
+	return posts[0], nil
+}
+
+// GetBySlug finds a post by its current slug or any slug it had before a rename.
+// Callers can compare the returned post's Slug with the one they asked for to redirect.
+// Trashed posts are found too, their slugs stay reserved until they are purged.
+func (s *sqlStore) GetBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	if slug == "" {
+		return nil, ErrNotFound
+	}
+	posts, err := s.queryPosts(ctx,
+		`SELECT `+postColumns+` FROM posts WHERE slug = $1 OR id IN (SELECT post_id FROM post_slugs WHERE slug = $1)`,
+		slug,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, ErrNotFound
+	}
+	return posts[0], nil
+}
+
+// FindByTitle returns the live posts titled title, ignoring case
+func (s *sqlStore) FindByTitle(ctx context.Context, title string) ([]*models.Post, error) {
+	return s.queryPosts(ctx, `SELECT `+postColumns+` FROM posts WHERE lower(name) = lower($1) AND deleted_at IS NULL`, title)
+}
+
+func (s *sqlStore) GetAll(ctx context.Context) ([]*models.Post, error) {
+	listOfPosts, err := s.queryPosts(ctx, `SELECT `+postColumns+` FROM posts WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the same contract as InMemoryStore
+	if len(listOfPosts) == 0 {
+		return nil, ErrEmptyStore
+	}
+	return listOfPosts, nil
+}
+
+// Trash moves a live post to the trash, it disappears from every read but GetBySlug and ListTrash
+func (s *sqlStore) Trash(ctx context.Context, id string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE posts SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`, s.timeArg(at), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// TrashAll moves every live post to the trash
+func (s *sqlStore) TrashAll(ctx context.Context, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE posts SET deleted_at = $1 WHERE deleted_at IS NULL`, s.timeArg(at))
+	return err
+}
+
+// Restore takes a post back out of the trash, ErrNotFound unless it is in there
+func (s *sqlStore) Restore(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE posts SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// GetTrashed finds a trashed post by id, live posts are ErrNotFound
+func (s *sqlStore) GetTrashed(ctx context.Context, id string) (*models.Post, error) {
+	posts, err := s.queryPosts(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, ErrNotFound
+	}
+	return posts[0], nil
+}
+
+// ListTrash returns one page of trashed posts, most recently deleted first.
+// The query is expected to have gone through TrashQuery.Validate already.
+func (s *sqlStore) ListTrash(ctx context.Context, q models.TrashQuery) ([]*models.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE deleted_at IS NOT NULL`
+	args := []any{}
+	if q.AuthorID != "" {
+		args = append(args, q.AuthorID)
+		query += ` AND author_id = $1`
+	}
+	args = append(args, q.Limit, q.Offset)
+	query += fmt.Sprintf(` ORDER BY deleted_at DESC, id LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+	return s.queryPosts(ctx, query, args...)
+}
+
+// Purge permanently deletes every post trashed before deletedBefore and returns their ids.
+// Tags, slug history, comments and revisions go with them through ON DELETE CASCADE.
+func (s *sqlStore) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	var purged []string
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `DELETE FROM posts WHERE deleted_at < $1 RETURNING id`, s.timeArg(deletedBefore))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		purged = []string{}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			purged = append(purged, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(purged)
+	return purged, nil
+}
+
+// Delete permanently removes a post, live or trashed
+func (s *sqlStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM posts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// DeleteAll permanently removes every post, trashed ones included
+func (s *sqlStore) DeleteAll(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM posts`)
+	return err
+}
+
+// queryPosts runs a SELECT of postColumns and fills in the tags of every post it returns
+func (s *sqlStore) queryPosts(ctx context.Context, query string, args ...any) ([]*models.Post, error) {
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.WarnContext(ctx, "post query failed", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	listOfPosts := []*models.Post{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		listOfPosts = append(listOfPosts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachTags(ctx, listOfPosts); err != nil {
+		return nil, err
+	}
+	slog.DebugContext(ctx, "post query", "rows", len(listOfPosts), "duration", time.Since(start))
+	return listOfPosts, nil
+}
+
+// tagBatchSize is how many posts attachTags looks up per query, it stays well below the bound
+// parameter limits of sqlite (32766) and postgres (65535)
+const tagBatchSize = 500
+
+// attachTags loads the tags of all posts with one query per batch instead of one per post
+func (s *sqlStore) attachTags(ctx context.Context, posts []*models.Post) error {
+	for batch := range slices.Chunk(posts, tagBatchSize) {
+		if err := s.attachTagBatch(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) attachTagBatch(ctx context.Context, posts []*models.Post) error {
+	byID := make(map[string]*models.Post, len(posts))
+	placeholders := make([]string, len(posts))
+	args := make([]any, len(posts))
+	for i, post := range posts {
+		byID[post.ID] = post
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = post.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT post_id, tag FROM post_tags WHERE post_id IN (`+strings.Join(placeholders, ", ")+`) ORDER BY tag`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, tag string
+		if err := rows.Scan(&postID, &tag); err != nil {
+			return err
+		}
+		byID[postID].Tags = append(byID[postID].Tags, tag)
+	}
+	return rows.Err()
+}
+
+// checkSlug returns ErrAlreadyExists when another post has, or used to have, slug
+func checkSlug(ctx context.Context, tx *sql.Tx, slug, postID string) error {
+	if slug == "" {
+		return nil
+	}
+	var taken bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM posts WHERE slug = $1 AND id <> $2)
+			OR EXISTS (SELECT 1 FROM post_slugs WHERE slug = $1 AND post_id <> $2)`,
+		slug, postID,
+	).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// replaceTags makes the post's rows in post_tags match tags exactly
+func replaceTags(ctx context.Context, tx *sql.Tx, postID string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM post_tags WHERE post_id = $1`, postID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO post_tags (post_id, tag) VALUES ($1, $2)`, postID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		slog.DebugContext(ctx, "transaction rolled back", "error", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// expectAffected turns "no rows matched" into ErrNotFound
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// postColumns must stay in the same order as the Scan call in scanPost
+const postColumns = `id, name, content, slug, author_id, category, status, publish_at, created_at, updated_at, deleted_at`
+
+// scanner is satisfied by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPost(row scanner) (*models.Post, error) {
+	var post models.Post
+	var publishAt, createdAt, updatedAt time.Time
+	var deletedAt sql.NullTime // NULL while the post is live
+	if err := row.Scan(
+		&post.ID, &post.Name, &post.Content, &post.Slug, &post.AuthorID, &post.Category,
+		&post.Status, &publishAt, &createdAt, &updatedAt, &deletedAt,
+	); err != nil {
+		return nil, err
+	}
+	post.PublishAt = publishAt.UTC()
+	post.CreatedAt = createdAt.UTC()
+	post.UpdatedAt = updatedAt.UTC()
+	if deletedAt.Valid {
+		post.DeletedAt = deletedAt.Time.UTC()
+	}
+	return &post, nil
+}
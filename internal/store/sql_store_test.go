@@ -0,0 +1,170 @@
+package store
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// runSQLStoreTests is shared by every sqlStore backend, newStore must return an empty store
+func runSQLStoreTests(t *testing.T, newStore func(t *testing.T) *sqlStore) {
+	t.Run("GetByID and Create", func(t *testing.T) {
+		s := newStore(t)
+
+		if err := s.Create(t.Context(), nil); err == nil {
+			t.Fatal("expected error for nil post, got nil")
+		}
+
+		post, _ := models.NewPost("Test Title", "Test Content")
+		if err := s.Create(t.Context(), post); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := s.GetByID(t.Context(), post.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != post.Name || got.Content != post.Content || !got.CreatedAt.Equal(post.CreatedAt) {
+			t.Errorf("stored post mismatch: got %+v, want %+v", got, post)
+		}
+
+		if _, err := s.GetByID(t.Context(), "somerandomnonsenseid"); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("GetAll and DeleteAll", func(t *testing.T) {
+		s := newStore(t)
+
+		for i := range 5 {
+			post, _ := models.NewPost("Title"+strconv.Itoa(i), "Test Content"+strconv.Itoa(i))
+			s.Create(t.Context(), post)
+		}
+
+		listOfPosts, err := s.GetAll(t.Context())
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(listOfPosts) != 5 {
+			t.Errorf("Expected 5 posts, got %d", len(listOfPosts))
+		}
+
+		if err := s.DeleteAll(t.Context()); err != nil {
+			t.Fatalf("Expected to delete all posts but failed: %v", err)
+		}
+		if _, err := s.GetAll(t.Context()); err != ErrEmptyStore {
+			t.Errorf("Got error %v wanted error %v", err, ErrEmptyStore)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		s := newStore(t)
+
+		post, _ := models.NewPost("Title", "Test Content")
+		post.Tags = []string{"go", "old"}
+		s.Create(t.Context(), post)
+
+		post.Edit("New Title", "New Content", time.Now())
+		post.Tags = []string{"go", "new"}
+		post.Category = "notes"
+		if err := s.Update(t.Context(), post); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, _ := s.GetByID(t.Context(), post.ID)
+		if got.Name != "New Title" || got.Content != "New Content" || !got.UpdatedAt.Equal(post.UpdatedAt) {
+			t.Errorf("update not stored, got %+v want %+v", got, post)
+		}
+		if !slices.Equal(got.Tags, post.Tags) || got.Category != "notes" {
+			t.Errorf("tags or category not replaced, got %v %q", got.Tags, got.Category)
+		}
+
+		missing, _ := models.NewPost("Missing", "Not in the store")
+		if err := s.Update(t.Context(), missing); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("tags load for more posts than fit in one batch", func(t *testing.T) {
+		s := newStore(t)
+
+		var first, last *models.Post
+		for i := range tagBatchSize + 1 {
+			post, _ := models.NewPost("Post "+strconv.Itoa(i), "Test Content")
+			if i == 0 || i == tagBatchSize {
+				post.Tags = []string{"edge"}
+			}
+			if err := s.Create(t.Context(), post); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if i == 0 {
+				first = post
+			}
+			last = post
+		}
+
+		posts, err := s.GetAll(t.Context())
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		tagged := 0
+		for _, post := range posts {
+			if len(post.Tags) > 0 {
+				tagged++
+				if post.ID != first.ID && post.ID != last.ID {
+					t.Errorf("Post %s got tags %v", post.Name, post.Tags)
+				}
+			}
+		}
+		if len(posts) != tagBatchSize+1 || tagged != 2 {
+			t.Errorf("Got %d posts with %d tagged, want %d with 2", len(posts), tagged, tagBatchSize+1)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		s := newStore(t)
+
+		post, _ := models.NewPost("Title", "Test Content")
+		other, _ := models.NewPost("Other", "Other Content")
+		s.Create(t.Context(), post)
+		s.Create(t.Context(), other)
+
+		if err := s.Delete(t.Context(), post.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := s.GetByID(t.Context(), post.ID); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+		if _, err := s.GetByID(t.Context(), other.ID); err != nil {
+			t.Errorf("Delete removed the wrong post: %v", err)
+		}
+		if err := s.Delete(t.Context(), post.ID); err != ErrNotFound {
+			t.Errorf("Got error %v wanted error %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("a failed revision rolls the post back", func(t *testing.T) {
+		s := newStore(t)
+
+		post, _ := models.NewPost("Title", "Test Content")
+		// no post has this id, the foreign key fails the revision after the post is written
+		orphan := &models.Revision{PostID: "missing", Name: "Title", Content: "Test Content"}
+		if err := s.CreateWithRevision(t.Context(), post, orphan); err == nil {
+			t.Fatal("expected the revision to fail, got nil")
+		}
+		if _, err := s.GetByID(t.Context(), post.ID); err != ErrNotFound {
+			t.Errorf("expected the post to be rolled back, got error %v", err)
+		}
+
+		s.Create(t.Context(), post)
+		post.Edit("Edited", "Edited Content", time.Now())
+		if err := s.UpdateWithRevision(t.Context(), post, orphan); err == nil {
+			t.Fatal("expected the revision to fail, got nil")
+		}
+		if got, _ := s.GetByID(t.Context(), post.ID); got.Name != "Title" {
+			t.Errorf("expected the update to be rolled back, got %q", got.Name)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	// pure Go sqlite driver, registers itself as "sqlite" so we don't need cgo
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTimeLayout is fixed width so timestamps sort correctly as text.
+// The driver still parses it back into time.Time because the column is declared TIMESTAMP.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// SQLiteStore persists posts in an embedded sqlite database file
+type SQLiteStore struct {
+	*sqlStore
+}
+
+// NewSQLiteStore opens (or creates) the database at path and migrates it to the latest schema
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %q: %w", path, err)
+	}
+
+	// sqlite only allows one writer at a time, a single connection avoids SQLITE_BUSY
+	// and also keeps ":memory:" databases from being split across connections
+	db.SetMaxOpenConns(1)
+
+	if err := migrateUp(db, "sqlite"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate sqlite: %w", err)
+	}
+
+	return &SQLiteStore{
+		sqlStore: &sqlStore{
+			db: db,
+			timeArg: func(t time.Time) any {
+				return t.UTC().Format(sqliteTimeLayout)
+			},
+		},
+	}, nil
+}
+
+// sqliteDSN turns a file path into a sqlite URI. The path is escaped so a '?' or '#' in it
+// can't end the file name early and smuggle in parameters.
+func sqliteDSN(path string) string {
+	escaped := (&url.URL{Path: path}).EscapedPath()
+	// sqlite leaves foreign keys off unless asked, every connection has to turn them on
+	return "file:" + escaped + "?_pragma=foreign_keys(1)"
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "goblog.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStore(t *testing.T) {
+	runSQLStoreTests(t, func(t *testing.T) *sqlStore {
+		return newTestSQLiteStore(t).sqlStore
+	})
+}
+
+func TestSQLiteStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goblog.db")
+
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	post, _ := models.NewPost("Persistent", "Survives a restart")
+	if err := s.Create(t.Context(), post); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	s.Close()
+
+	// reopening also re-runs the migrations, which must be a no-op
+	reopened, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.GetByID(t.Context(), post.ID)
+	if err != nil {
+		t.Fatalf("GetByID after reopen: %v", err)
+	}
+	if got.Name != post.Name {
+		t.Errorf("Expected %q, got %q", post.Name, got.Name)
+	}
+}
+
+func TestSQLiteStore_PathNeedsEscaping(t *testing.T) {
+	// without escaping, "?" starts the query and "#" a fragment, so sqlite would open "odd"
+	dir := filepath.Join(t.TempDir(), "odd?mode=ro#100%")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "goblog.db")
+
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	post, _ := models.NewPost("Escaped", "Lands in the right file")
+	if err := s.Create(t.Context(), post); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the database at %s: %v", path, err)
+	}
+}
+
+func TestSQLiteStore_RelativePath(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	s, err := NewSQLiteStore("goblog.db")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := os.Stat("goblog.db"); err != nil {
+		t.Errorf("Expected the database in the working directory: %v", err)
+	}
+}
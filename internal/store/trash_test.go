@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/models"
+)
+
+// trashStore is the part of the store API the trash tests need,
+// so the same cases run against the in-memory store and every SQL backend
+type trashStore interface {
+	Create(context.Context, *models.Post) error
+	Update(context.Context, *models.Post) error
+	GetAll(ctx context.Context) ([]*models.Post, error)
+	FindByTitle(ctx context.Context, title string) ([]*models.Post, error)
+	GetByID(context.Context, string) (*models.Post, error)
+	GetBySlug(context.Context, string) (*models.Post, error)
+	List(context.Context, models.PostQuery) ([]*models.Post, error)
+	TagCounts(ctx context.Context) ([]models.TagCount, error)
+	Trash(ctx context.Context, id string, at time.Time) error
+	TrashAll(ctx context.Context, at time.Time) error
+	Restore(ctx context.Context, id string) error
+	GetTrashed(ctx context.Context, id string) (*models.Post, error)
+	ListTrash(ctx context.Context, q models.TrashQuery) ([]*models.Post, error)
+	Purge(ctx context.Context, deletedBefore time.Time) ([]string, error)
+	Counts(ctx context.Context) (models.PostCounts, error)
+}
+
+func runTrashTests(t *testing.T, newStore func(t *testing.T) trashStore) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := func(t *testing.T, s trashStore, names ...string) []*models.Post {
+		t.Helper()
+		posts := []*models.Post{}
+		for _, name := range names {
+			post, _ := models.NewPost(name, "Some content")
+			post.Tags = []string{"go"}
+			if err := s.Create(t.Context(), post); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			posts = append(posts, post)
+		}
+		return posts
+	}
+
+	t.Run("counts split live posts by status from trashed ones", func(t *testing.T) {
+		s := newStore(t)
+		posts := seed(t, s, "One", "Two", "Three", "Four")
+		posts[1].Status = models.StatusDraft
+		if err := s.Update(t.Context(), posts[1]); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		for _, post := range posts[2:] {
+			if err := s.Trash(t.Context(), post.ID, base); err != nil {
+				t.Fatalf("Trash: %v", err)
+			}
+		}
+
+		counts, err := s.Counts(t.Context())
+		if err != nil {
+			t.Fatalf("Counts: %v", err)
+		}
+		want := map[models.PostStatus]int{models.StatusPublished: 1, models.StatusDraft: 1}
+		if !maps.Equal(counts.ByStatus, want) || counts.Trashed != 2 {
+			t.Errorf("got %+v, want %v and 2 trashed", counts, want)
+		}
+	})
+
+	t.Run("trashed posts are hidden", func(t *testing.T) {
+		s := newStore(t)
+		posts := seed(t, s, "Kept", "Binned")
+		binned := posts[1]
+
+		if err := s.Trash(t.Context(), binned.ID, base); err != nil {
+			t.Fatalf("Trash: %v", err)
+		}
+		if err := s.Trash(t.Context(), binned.ID, base); err != ErrNotFound {
+			t.Errorf("trashing twice: got error %v wanted error %v", err, ErrNotFound)
+		}
+		if err := s.Trash(t.Context(), "nope", base); err != ErrNotFound {
+			t.Errorf("got error %v wanted error %v", err, ErrNotFound)
+		}
+
+		if _, err := s.GetByID(t.Context(), binned.ID); err != ErrNotFound {
+			t.Errorf("GetByID: got error %v wanted error %v", err, ErrNotFound)
+		}
+		if all, _ := s.GetAll(t.Context()); len(all) != 1 || all[0].ID != posts[0].ID {
+			t.Errorf("GetAll returned %d posts, expected only the live one", len(all))
+		}
+		if page, _ := s.List(t.Context(), models.PostQuery{Limit: 10}); len(page) != 1 {
+			t.Errorf("List returned %d posts, expected only the live one", len(page))
+		}
+		if found, _ := s.FindByTitle(t.Context(), "Binned"); len(found) != 0 {
+			t.Errorf("FindByTitle returned the trashed post: %+v", found)
+		}
+		if tags, _ := s.TagCounts(t.Context()); len(tags) != 1 || tags[0].Count != 1 {
+			t.Errorf("TagCounts counted the trashed post: %+v", tags)
+		}
+		binned.Edit("Edited", "Edited content", time.Now())
+		if err := s.Update(t.Context(), binned); err != ErrNotFound {
+			t.Errorf("Update: got error %v wanted error %v", err, ErrNotFound)
+		}
+
+		// the slug stays reserved so a restore can't collide
+		if got, err := s.GetBySlug(t.Context(), "binned"); err != nil || got.DeletedAt.IsZero() {
+			t.Errorf("expected GetBySlug to find the trashed post, got %+v, %v", got, err)
+		}
+	})
+
+	t.Run("list and restore", func(t *testing.T) {
+		s := newStore(t)
+		posts := seed(t, s, "First", "Second", "Live")
+		s.Trash(t.Context(), posts[0].ID, base)
+		s.Trash(t.Context(), posts[1].ID, base.Add(time.Hour))
+
+		trash, err := s.ListTrash(t.Context(), models.TrashQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListTrash: %v", err)
+		}
+		if len(trash) != 2 || trash[0].ID != posts[1].ID || !trash[0].DeletedAt.Equal(base.Add(time.Hour)) {
+			t.Fatalf("expected the most recently deleted first, got %+v", trash)
+		}
+		if len(trash[1].Tags) != 1 {
+			t.Errorf("expected trashed posts to keep their tags, got %v", trash[1].Tags)
+		}
+
+		got, err := s.GetTrashed(t.Context(), posts[1].ID)
+		if err != nil || got.Name != "Second" || len(got.Tags) != 1 {
+			t.Errorf("GetTrashed: got %+v, %v", got, err)
+		}
+		for _, id := range []string{posts[2].ID, "nope"} {
+			if _, err := s.GetTrashed(t.Context(), id); err != ErrNotFound {
+				t.Errorf("GetTrashed(%q): got error %v wanted error %v", id, err, ErrNotFound)
+			}
+		}
+
+		if err := s.Restore(t.Context(), posts[0].ID); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if err := s.Restore(t.Context(), posts[2].ID); err != ErrNotFound {
+			t.Errorf("restoring a live post: got error %v wanted error %v", err, ErrNotFound)
+		}
+		got, err = s.GetByID(t.Context(), posts[0].ID)
+		if err != nil {
+			t.Fatalf("GetByID after restore: %v", err)
+		}
+		if !got.DeletedAt.IsZero() {
+			t.Errorf("expected DeletedAt to be cleared, got %v", got.DeletedAt)
+		}
+	})
+
+	t.Run("list filters by author and pages", func(t *testing.T) {
+		s := newStore(t)
+		posts := []*models.Post{}
+		for i, author := range []string{"alice", "bob", "alice", "alice"} {
+			post, _ := models.NewPost(fmt.Sprintf("Post %d", i), "Some content")
+			post.AuthorID = author
+			if err := s.Create(t.Context(), post); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := s.Trash(t.Context(), post.ID, base.Add(time.Duration(i)*time.Hour)); err != nil {
+				t.Fatalf("Trash: %v", err)
+			}
+			posts = append(posts, post)
+		}
+
+		tests := []struct {
+			name  string
+			query models.TrashQuery
+			want  []string
+		}{
+			{name: "everyone's", query: models.TrashQuery{Limit: 10}, want: []string{posts[3].ID, posts[2].ID, posts[1].ID, posts[0].ID}},
+			{name: "one author", query: models.TrashQuery{Limit: 10, AuthorID: "alice"}, want: []string{posts[3].ID, posts[2].ID, posts[0].ID}},
+			{name: "first page", query: models.TrashQuery{Limit: 2, AuthorID: "alice"}, want: []string{posts[3].ID, posts[2].ID}},
+			{name: "second page", query: models.TrashQuery{Limit: 2, Offset: 2, AuthorID: "alice"}, want: []string{posts[0].ID}},
+			{name: "past the end", query: models.TrashQuery{Limit: 2, Offset: 5}, want: []string{}},
+			{name: "nobody's", query: models.TrashQuery{Limit: 10, AuthorID: "carol"}, want: []string{}},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				trash, err := s.ListTrash(t.Context(), tc.query)
+				if err != nil {
+					t.Fatalf("ListTrash: %v", err)
+				}
+				ids := []string{}
+				for _, post := range trash {
+					ids = append(ids, post.ID)
+				}
+				if !slices.Equal(ids, tc.want) {
+					t.Errorf("got %v wanted %v", ids, tc.want)
+				}
+			})
+		}
+	})
+
+	t.Run("trash all and purge", func(t *testing.T) {
+		s := newStore(t)
+		posts := seed(t, s, "Old", "New")
+		s.Trash(t.Context(), posts[0].ID, base)
+		if err := s.TrashAll(t.Context(), base.Add(48*time.Hour)); err != nil {
+			t.Fatalf("TrashAll: %v", err)
+		}
+		if _, err := s.GetAll(t.Context()); err != ErrEmptyStore {
+			t.Errorf("got error %v wanted error %v", err, ErrEmptyStore)
+		}
+
+		purged, err := s.Purge(t.Context(), base.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if !slices.Equal(purged, []string{posts[0].ID}) {
+			t.Errorf("expected only the old post to be purged, got %v", purged)
+		}
+		if trash, _ := s.ListTrash(t.Context(), models.TrashQuery{Limit: 10}); len(trash) != 1 || trash[0].ID != posts[1].ID {
+			t.Errorf("expected the newer post to stay in the trash, got %d", len(trash))
+		}
+		if _, err := s.GetBySlug(t.Context(), "old"); err != ErrNotFound {
+			t.Errorf("expected the purged post's slug to be free, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryStore_Trash(t *testing.T) {
+	runTrashTests(t, func(t *testing.T) trashStore { return NewInMemoryStore() })
+}
+
+func TestSQLiteStore_Trash(t *testing.T) {
+	runTrashTests(t, func(t *testing.T) trashStore { return newTestSQLiteStore(t) })
+}
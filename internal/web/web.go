@@ -0,0 +1,194 @@
+// Package web is the server-rendered HTML side of the blog: an index of posts and a page per post,
+// rendered with html/template from the same PostServiceRepository the JSON API uses.
+// Templates and static assets are embedded so the binary is all you need to deploy.
+package web
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+//go:embed static
+var staticFS embed.FS
+
+// PageSize is how many posts the index shows at once
+const PageSize = 10
+
+var funcs = template.FuncMap{
+	"isoDate":   func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	"humanDate": func(t time.Time) string { return t.UTC().Format("January 2, 2006") },
+	"postURL":   PostURL,
+}
+
+// PostURL is the page of a post, by slug when it has one
+func PostURL(post *models.Post) string {
+	if post.Slug == "" {
+		return "/p/" + url.PathEscape(post.ID)
+	}
+	return "/p/" + url.PathEscape(post.Slug)
+}
+
+// Handler serves the HTML pages
+type Handler struct {
+	Posts *service.PostServiceRepository
+
+	// one template set per page, each is the layout plus that page's blocks
+	pages map[string]*template.Template
+}
+
+// NewHandler parses the embedded templates, a broken template fails here instead of on the first request
+func NewHandler(posts *service.PostServiceRepository) (*Handler, error) {
+	h := &Handler{Posts: posts, pages: map[string]*template.Template{}}
+
+	for _, page := range []string{"index", "post", "error"} {
+		tmpl, err := template.New(page).Funcs(funcs).ParseFS(templateFS, "templates/layout.html", "templates/"+page+".html")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s template: %w", page, err)
+		}
+		h.pages[page] = tmpl
+	}
+	return h, nil
+}
+
+// Static serves the embedded assets, mount it on /static/
+func Static() http.Handler {
+	sub, _ := fs.Sub(staticFS, "static") // the directory is embedded, this can't fail
+	return http.StripPrefix("/static/", http.FileServerFS(sub))
+}
+
+type indexPage struct {
+	Posts   []*models.Post
+	Tag     string
+	PrevURL string
+	NextURL string
+}
+
+// Index handles GET /, newest posts first, ?page=N for older ones and ?tag= to narrow them down
+func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
+	pageNum := 1
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			h.renderError(w, http.StatusBadRequest, "Bad request", "That page number doesn't look right.")
+			return
+		}
+		pageNum = n
+	}
+	tag := service.NormalizeFilter(r.URL.Query().Get("tag"))
+
+	page, err := h.Posts.ListPosts(r.Context(), models.PostQuery{
+		Limit:      PageSize,
+		Offset:     (pageNum - 1) * PageSize,
+		SortBy:     models.SortByCreatedAt,
+		Descending: true,
+		Tag:        tag,
+		PublicOnly: true,
+	})
+	if err != nil {
+		h.serverError(w, r, err)
+		return
+	}
+
+	data := indexPage{Posts: page.Posts, Tag: tag}
+	if pageNum > 1 {
+		data.PrevURL = pageURL(tag, pageNum-1)
+	}
+	if page.HasMore {
+		data.NextURL = pageURL(tag, pageNum+1)
+	}
+	h.render(w, http.StatusOK, "index", data)
+}
+
+// pageURL links to another page of the index, page 1 is plain /
+func pageURL(tag string, page int) string {
+	values := url.Values{}
+	if tag != "" {
+		values.Set("tag", tag)
+	}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if len(values) == 0 {
+		return "/"
+	}
+	return "/?" + values.Encode()
+}
+
+type postPage struct {
+	Post *models.Post
+	// Content is the sanitized HTML from the markdown renderer, so it is trusted as is
+	Content template.HTML
+}
+
+// Post handles GET /p/{key}, key being a slug or, for links from before slugs, the post id.
+// Anything but the current slug redirects there so every post has one canonical URL.
+func (h *Handler) Post(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+
+	post, err := h.Posts.GetPostBySlug(r.Context(), key)
+	if errors.Is(err, store.ErrNotFound) {
+		post, err = h.Posts.GetPostByID(r.Context(), key)
+	}
+	if err == nil && !post.IsPublic() {
+		err = store.ErrNotFound // drafts are previewed through the API, the site only shows what is out
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		h.renderError(w, http.StatusNotFound, "Not found", "There is no post here.")
+		return
+	}
+	if err != nil {
+		h.serverError(w, r, err)
+		return
+	}
+
+	if canonical := PostURL(post); canonical != r.URL.Path {
+		http.Redirect(w, r, canonical, http.StatusMovedPermanently)
+		return
+	}
+
+	h.render(w, http.StatusOK, "post", postPage{Post: post, Content: template.HTML(post.ContentHTML)})
+}
+
+type errorPage struct {
+	Title   string
+	Message string
+}
+
+func (h *Handler) renderError(w http.ResponseWriter, status int, title, message string) {
+	h.render(w, status, "error", errorPage{Title: title, Message: message})
+}
+
+func (h *Handler) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+	h.renderError(w, http.StatusInternalServerError, "Something went wrong", "Please try again later.")
+}
+
+// render executes into a buffer first so a failing template doesn't leave half a page behind a 200
+func (h *Handler) render(w http.ResponseWriter, status int, page string, data any) {
+	var buf bytes.Buffer
+	if err := h.pages[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		slog.Error("render failed", "page", page, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
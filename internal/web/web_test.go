@@ -0,0 +1,185 @@
+package web
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aziz-shoko/goblog/internal/service"
+	"github.com/aziz-shoko/goblog/internal/store"
+	"github.com/aziz-shoko/goblog/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// newTestHandler seeds posts with fixed ids and dates so the rendered pages are byte for byte stable
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	s := store.NewInMemoryStore()
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := range PageSize + 2 {
+		post := &models.Post{
+			ID:        fmt.Sprintf("post-%02d", i),
+			Name:      fmt.Sprintf("Post number %d", i),
+			Content:   fmt.Sprintf("Content of post %d", i),
+			Status:    models.StatusPublished,
+			CreatedAt: base.Add(time.Duration(i) * 24 * time.Hour),
+		}
+		post.UpdatedAt, post.PublishAt = post.CreatedAt, post.CreatedAt
+		if i%4 == 0 {
+			post.Tags = []string{"go"}
+		}
+		s.Create(t.Context(), post)
+	}
+	s.Create(t.Context(), &models.Post{
+		ID:        "markdown",
+		Slug:      "markdown-friends",
+		Name:      "Markdown & <friends>",
+		Content:   "Some **bold** text.\n\n```go\nfmt.Println(\"hi\")\n```\n\n<script>alert(1)</script>",
+		AuthorID:  "alice",
+		Category:  "tutorials",
+		Tags:      []string{"go", "markdown"},
+		Status:    models.StatusPublished,
+		PublishAt: base.Add(-24 * time.Hour),
+		CreatedAt: base.Add(-24 * time.Hour),
+		UpdatedAt: base.Add(-24 * time.Hour),
+	})
+	// newest of all, but not out yet
+	s.Create(t.Context(), &models.Post{
+		ID:        "draft",
+		Slug:      "secret-draft",
+		Name:      "Secret draft",
+		Content:   "Not for readers",
+		Tags:      []string{"go"},
+		Status:    models.StatusDraft,
+		CreatedAt: base.Add(365 * 24 * time.Hour),
+		UpdatedAt: base.Add(365 * 24 * time.Hour),
+	})
+
+	h, err := NewHandler(service.NewPostService(s))
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+	return h
+}
+
+func TestPages(t *testing.T) {
+	h := newTestHandler(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		key        string
+		serve      func(http.ResponseWriter, *http.Request)
+		wantStatus int
+	}{
+		{name: "index", target: "/", serve: h.Index, wantStatus: http.StatusOK},
+		{name: "index_page_2", target: "/?page=2", serve: h.Index, wantStatus: http.StatusOK},
+		{name: "index_tag", target: "/?tag=go", serve: h.Index, wantStatus: http.StatusOK},
+		{name: "index_bad_page", target: "/?page=0", serve: h.Index, wantStatus: http.StatusBadRequest},
+		{name: "post", target: "/p/markdown-friends", key: "markdown-friends", serve: h.Post, wantStatus: http.StatusOK},
+		{name: "post_without_slug", target: "/p/post-03", key: "post-03", serve: h.Post, wantStatus: http.StatusOK},
+		{name: "post_not_found", target: "/p/nope", key: "nope", serve: h.Post, wantStatus: http.StatusNotFound},
+		{name: "draft_not_found", target: "/p/secret-draft", key: "secret-draft", serve: h.Post, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			req.SetPathValue("key", tc.key)
+			w := httptest.NewRecorder()
+			tc.serve(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected status code %d but got %d", tc.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+				t.Errorf("unexpected content type %q", ct)
+			}
+			assertGolden(t, tc.name, w.Body.String())
+		})
+	}
+}
+
+func TestIndex_TagFilter(t *testing.T) {
+	s := store.NewInMemoryStore()
+	posts := service.NewPostService(s)
+	if _, err := posts.CreatePost(t.Context(), "Tagged", "Some content", service.WithTags("Web Dev")); err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	h, err := NewHandler(posts)
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+
+	// the same spellings GET /posts?tag= accepts
+	for _, tag := range []string{"web-dev", "Web%20Dev", "web++dev"} {
+		w := httptest.NewRecorder()
+		h.Index(w, httptest.NewRequest(http.MethodGet, "/?tag="+tag, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Tagged") {
+			t.Errorf("?tag=%s: expected the web-dev post, got %d:\n%s", tag, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestPost_Redirects(t *testing.T) {
+	h := newTestHandler(t)
+
+	// links by id from before slugs, and slugs from before a rename, end up on the current slug
+	title := "Markdown and friends"
+	if _, err := h.Posts.UpdatePost(t.Context(), "markdown", "", &title, nil); err != nil {
+		t.Fatalf("UpdatePost: %v", err)
+	}
+
+	for _, key := range []string{"markdown", "markdown-friends"} {
+		req := httptest.NewRequest(http.MethodGet, "/p/"+key, nil)
+		req.SetPathValue("key", key)
+		w := httptest.NewRecorder()
+		h.Post(w, req)
+
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/p/markdown-and-friends" {
+			t.Errorf("/p/%s: got %d to %q", key, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/static/style.css", nil)
+	w := httptest.NewRecorder()
+	Static().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+		t.Errorf("unexpected content type %q", w.Header().Get("Content-Type"))
+	}
+}
+
+// assertGolden compares got with testdata/<name>.golden, run with -update after an intended change
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match the golden file\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
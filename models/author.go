@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmptyAuthorID   = errors.New("author id cannot be empty")
+	ErrEmptyAuthorName = errors.New("author name cannot be empty")
+)
+
+// Author is the public profile of someone who writes posts.
+// The ID is the authenticated principal's ID, so a token or api key maps straight to an author.
+type Author struct {
+	ID        string
+	Name      string
+	Bio       string
+	CreatedAt time.Time
+}
+
+func NewAuthor(id, name, bio string) (*Author, error) {
+	if id == "" {
+		return nil, ErrEmptyAuthorID
+	} else if name == "" {
+		return nil, ErrEmptyAuthorName
+	}
+
+	return &Author{
+		ID:        id,
+		Name:      name,
+		Bio:       bio,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
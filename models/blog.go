@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -12,10 +13,23 @@ var (
 )
 
 type Post struct {
-	Name      string
-	Content   string
-	ID        string
+	Name    string
+	Content string
+	// ContentHTML is Content rendered to sanitized HTML, it is derived by the service and never stored
+	ContentHTML string
+	ID          string
+	// Slug is the post's human friendly URL segment, unique across all posts
+	Slug     string
+	AuthorID string
+	Category string
+	Tags     []string
+	Status   PostStatus
+	// PublishAt is when the post went, or is going, public. Zero for drafts.
+	PublishAt time.Time
 	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is when the post was moved to the trash, zero for live posts
+	DeletedAt time.Time
 }
 
 func NewPost(name, content string) (*Post, error) {
@@ -25,10 +39,31 @@ func NewPost(name, content string) (*Post, error) {
 		return nil, ErrEmtpyContent
 	}
 
+	now := time.Now().UTC()
 	return &Post{
 		Name:      name,
 		Content:   content,
 		ID:        uuid.NewString(),
-		CreatedAt: time.Now().UTC(),
+		Slug:      Slugify(name),
+		Status:    StatusPublished,
+		PublishAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}, nil
 }
+
+// Edit replaces the title and content, applying the same domain validation as NewPost.
+// The slug follows the title, the service makes it unique again. at becomes UpdatedAt.
+func (p *Post) Edit(name, content string, at time.Time) error {
+	if name == "" {
+		return ErrEmtpyTitle
+	} else if content == "" {
+		return ErrEmtpyContent
+	}
+
+	p.Name = name
+	p.Slug = Slugify(name)
+	p.Content = content
+	p.UpdatedAt = at.UTC()
+	return nil
+}
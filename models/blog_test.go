@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -41,9 +42,128 @@ func TestNewPost_SideAffects(t *testing.T) {
 	})
 }
 
+func TestPost_Edit(t *testing.T) {
+	post, err := NewPost("title", "content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := post.UpdatedAt
+
+	t.Run("rejects empty fields", func(t *testing.T) {
+		AssertError(t, post.Edit("", "new content", time.Now()), ErrEmtpyTitle)
+		AssertError(t, post.Edit("new title", "", time.Now()), ErrEmtpyContent)
+		if post.Name != "title" || post.Content != "content" {
+			t.Errorf("failed edit should leave the post untouched, got %+v", post)
+		}
+	})
+
+	t.Run("updates fields and UpdatedAt", func(t *testing.T) {
+		edited := created.Add(time.Hour)
+		AssertError(t, post.Edit("new title", "new content", edited), nil)
+		if post.Name != "new title" || post.Content != "new content" {
+			t.Errorf("edit not applied, got %+v", post)
+		}
+		if !post.UpdatedAt.Equal(edited) {
+			t.Errorf("UpdatedAt should be the edit time %v, got %v", edited, post.UpdatedAt)
+		}
+		if !post.CreatedAt.Equal(created) {
+			t.Errorf("CreatedAt should not change")
+		}
+	})
+}
+
 func AssertError(t testing.TB, got, want error) {
 	t.Helper()
 	if got != want {
 		t.Errorf("Got %q wanted %q", got, want)
 	}
 }
+
+func TestPostQuery_Validate(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		Name    string
+		Query   PostQuery
+		WantErr error
+		Want    PostQuery
+	}{
+		{Name: "defaults", Query: PostQuery{}, Want: PostQuery{Limit: DefaultPageSize, SortBy: SortByCreatedAt}},
+		{Name: "negative offset is clamped", Query: PostQuery{Limit: 5, Offset: -3, SortBy: SortByName}, Want: PostQuery{Limit: 5, SortBy: SortByName}},
+		{Name: "limit too big", Query: PostQuery{Limit: MaxPageSize + 1}, WantErr: ErrInvalidLimit},
+		{Name: "negative limit", Query: PostQuery{Limit: -1}, WantErr: ErrInvalidLimit},
+		{Name: "unknown sort", Query: PostQuery{SortBy: "id"}, WantErr: ErrInvalidSort},
+		{Name: "backwards range", Query: PostQuery{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)}, WantErr: ErrInvalidRange},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			q := tc.Query
+			AssertError(t, q.Validate(), tc.WantErr)
+			if tc.WantErr == nil && q != tc.Want {
+				t.Errorf("Got %+v wanted %+v", q, tc.Want)
+			}
+		})
+	}
+}
+
+func TestNewAuthor_Validation(t *testing.T) {
+	cases := []struct {
+		Name       string
+		ID         string
+		AuthorName string
+		WantErr    error
+	}{
+		{Name: "valid", ID: "alice", AuthorName: "Alice"},
+		{Name: "empty id", ID: "", AuthorName: "Alice", WantErr: ErrEmptyAuthorID},
+		{Name: "empty name", ID: "alice", AuthorName: "", WantErr: ErrEmptyAuthorName},
+	}
+
+	for _, tc := range cases {
+		author, err := NewAuthor(tc.ID, tc.AuthorName, "")
+		AssertError(t, err, tc.WantErr)
+		if tc.WantErr == nil && (author.ID != tc.ID || author.CreatedAt.IsZero()) {
+			t.Errorf("unexpected author %+v", author)
+		}
+	}
+}
+
+func TestNewComment_Validation(t *testing.T) {
+	_, err := NewComment("", "", "alice", "body")
+	AssertError(t, err, ErrEmptyCommentPost)
+
+	_, err = NewComment("post", "", "alice", "")
+	AssertError(t, err, ErrEmptyComment)
+
+	comment, err := NewComment("post", "parent", "alice", "body")
+	AssertError(t, err, nil)
+	if comment.ID == "" || comment.ParentID != "parent" || time.Since(comment.CreatedAt) > time.Second {
+		t.Errorf("unexpected comment %+v", comment)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Title string
+		Want  string
+	}{
+		{Name: "words and punctuation", Title: "Hello, World! Go 1.24 is out", Want: "hello-world-go-1-24-is-out"},
+		{Name: "leading and trailing symbols", Title: "  --Why?--  ", Want: "why"},
+		{Name: "accents are stripped", Title: "Crème brûlée à la Café", Want: "creme-brulee-a-la-cafe"},
+		{Name: "special latin letters", Title: "Straße Øresund Łódź", Want: "strasse-oresund-lodz"},
+		{Name: "cyrillic", Title: "Привет мир", Want: "privet-mir"},
+		{Name: "greek", Title: "Καλημέρα", Want: "kalimera"},
+		{Name: "other scripts are kept", Title: "Go 入門", Want: "go-入門"},
+		{Name: "only symbols", Title: "!!!", Want: ""},
+		{Name: "long titles are cut at a word", Title: strings.Repeat("word ", 30), Want: strings.TrimSuffix(strings.Repeat("word-", 16), "-")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := Slugify(tc.Title); got != tc.Want {
+				t.Errorf("Got %q, want %q", got, tc.Want)
+			}
+		})
+	}
+}
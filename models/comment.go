@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyComment     = errors.New("comment cannot be empty")
+	ErrEmptyCommentPost = errors.New("comment must belong to a post")
+)
+
+// Comment is a reply to a post, or to another comment on the same post when ParentID is set
+type Comment struct {
+	ID        string
+	PostID    string
+	ParentID  string // empty for top level comments
+	AuthorID  string
+	Body      string
+	CreatedAt time.Time
+}
+
+func NewComment(postID, parentID, authorID, body string) (*Comment, error) {
+	if postID == "" {
+		return nil, ErrEmptyCommentPost
+	} else if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	return &Comment{
+		ID:        uuid.NewString(),
+		PostID:    postID,
+		ParentID:  parentID,
+		AuthorID:  authorID,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
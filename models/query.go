@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidSort  = errors.New("sort must be created_at or name")
+	ErrInvalidLimit = errors.New("limit must be between 1 and 100")
+	ErrInvalidRange = errors.New("created_after must be before created_before")
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByName      SortField = "name"
+)
+
+// PostQuery describes one page of a post listing.
+// It is a plain value so stores can translate it straight into SQL (WHERE/ORDER BY/LIMIT/OFFSET)
+// instead of loading every post and filtering in Go.
+type PostQuery struct {
+	Limit      int
+	Offset     int
+	SortBy     SortField
+	Descending bool
+
+	// only posts by this author, empty means everyone
+	AuthorID string
+	// only posts carrying this tag / in this category, empty means any
+	Tag      string
+	Category string
+
+	// zero time means no bound
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// only posts in this lifecycle state, empty means any
+	Status PostStatus
+	// PublicOnly hides everything that isn't published, except what Viewer wrote themselves
+	PublicOnly bool
+	Viewer     string
+	// only posts with a PublishAt at or before this, zero means no bound
+	PublishDueBy time.Time
+}
+
+// Validate fills in defaults and rejects values a store should never see
+func (q *PostQuery) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByName:
+	default:
+		return ErrInvalidSort
+	}
+
+	if q.Status != "" && !q.Status.Valid() {
+		return ErrInvalidStatus
+	}
+
+	if !q.CreatedAfter.IsZero() && !q.CreatedBefore.IsZero() && !q.CreatedAfter.Before(q.CreatedBefore) {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// TrashQuery describes one page of the trash, most recently deleted first
+type TrashQuery struct {
+	Limit  int
+	Offset int
+	// only posts by this author, empty means everyone's
+	AuthorID string
+}
+
+// Validate fills in defaults like PostQuery.Validate
+func (q *TrashQuery) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return nil
+}
+
+// TagCount is a tag and how many posts carry it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// PostCounts is how many posts a store holds, live ones by status and trashed ones on their own
+type PostCounts struct {
+	ByStatus map[PostStatus]int
+	Trashed  int
+}
+
+// PostPage is one page of results, HasMore tells the caller whether asking for the next offset is worth it
+type PostPage struct {
+	Posts   []*Post
+	HasMore bool
+}
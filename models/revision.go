@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Revision is an immutable snapshot of a post's title and content, one is recorded every time either changes.
+// Numbers start at 1 for the post as it was created and count up per post.
+type Revision struct {
+	PostID    string
+	Number    int
+	Name      string
+	Content   string
+	EditorID  string // whoever made the change, empty for anonymous writes
+	CreatedAt time.Time
+}
+
+// NewRevision snapshots the post as it is now, the store assigns the number
+func NewRevision(post *Post, editorID string) *Revision {
+	return &Revision{
+		PostID:    post.ID,
+		Name:      post.Name,
+		Content:   post.Content,
+		EditorID:  editorID,
+		CreatedAt: post.UpdatedAt,
+	}
+}
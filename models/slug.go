@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength keeps URLs readable, long titles are cut at a word boundary
+const MaxSlugLength = 80
+
+// transliterations covers letters that don't decompose into a base letter plus accents
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify turns a title into a lowercase, dash separated URL path segment.
+// Accents are stripped ("Café" -> "cafe"), Cyrillic and Greek are transliterated, letters
+// of other scripts are kept as they are. It can return "" for titles made only of symbols.
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	// NFD splits "é" into "e" plus a combining accent we can drop
+	for _, r := range norm.NFD.String(strings.ToLower(title)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		part, ok := transliterations[r]
+		if !ok {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				// everything else separates words, runs of it become a single dash
+				dash = b.Len() > 0
+				continue
+			}
+			part = string(r)
+		}
+		if part == "" {
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+
+	return truncateSlug(b.String())
+}
+
+// truncateSlug cuts at the last dash that fits, or hard at the limit if a single word is too long
+func truncateSlug(slug string) string {
+	runes := []rune(slug)
+	if len(runes) <= MaxSlugLength {
+		return slug
+	}
+	cut := string(runes[:MaxSlugLength])
+	if i := strings.LastIndexByte(cut, '-'); i > 0 {
+		return cut[:i]
+	}
+	return cut
+}
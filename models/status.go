@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrInvalidStatus = errors.New("status must be draft, published, scheduled or archived")
+
+// PostStatus is where a post is in its lifecycle, only published posts are public
+type PostStatus string
+
+const (
+	StatusDraft     PostStatus = "draft"
+	StatusPublished PostStatus = "published"
+	// scheduled posts turn published by themselves once PublishAt has passed
+	StatusScheduled PostStatus = "scheduled"
+	StatusArchived  PostStatus = "archived"
+)
+
+// Statuses lists every valid status
+var Statuses = []PostStatus{StatusDraft, StatusPublished, StatusScheduled, StatusArchived}
+
+func (s PostStatus) Valid() bool {
+	return slices.Contains(Statuses, s)
+}
+
+// IsPublic reports whether anyone may read the post, not just its author
+func (p *Post) IsPublic() bool {
+	return p.Status == StatusPublished
+}
+
+// Publish makes the post public at, or schedules it when at is still in the future
+func (p *Post) Publish(at, now time.Time) {
+	p.Status = StatusPublished
+	if at.After(now) {
+		p.Status = StatusScheduled
+	}
+	p.PublishAt = at.UTC()
+	p.UpdatedAt = now.UTC()
+}
+
+// Unpublish takes the post back to a draft
+func (p *Post) Unpublish(now time.Time) {
+	p.Status = StatusDraft
+	p.PublishAt = time.Time{}
+	p.UpdatedAt = now.UTC()
+}
+
+// Archive hides the post without deleting it, PublishAt is kept as a record of when it went out
+func (p *Post) Archive(now time.Time) {
+	p.Status = StatusArchived
+	p.UpdatedAt = now.UTC()
+}